@@ -38,27 +38,117 @@ type Price struct {
 	DSTFlag      bool `true:"Y" false:"N"`
 }
 
-func BenchmarkUnmarshal(b *testing.B) {
-	b.StopTimer()
-	data := loadData()
-	b.StartTimer()
+// DecimalPrice is Price with an exact LMP
+type DecimalPrice struct {
+	DeliveryDate string
+	HourEnding   string
+	BusName      string
+	LMP          Decimal
+	DSTFlag      bool `true:"Y" false:"N"`
+}
 
+// TestRoundTrip checks the benchmark data is unchanged by Unmarshal and Marshal
+func TestRoundTrip(t *testing.T) {
+	data := loadData()
 	pp := []Price{}
 
 	err := Unmarshal(data, &pp)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 
 	out, err := Marshal(pp)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 
 	if bytes.Equal(data, out) != true {
-		panic("wrong results")
+		t.Error("wrong results")
+	}
+}
+
+// TestRoundTripDecimal checks the data is unchanged when LMP is a Decimal,
+// including values a float32 can not hold.
+func TestRoundTripDecimal(t *testing.T) {
+	data := append(loadData(), "01/15/2015,01:00,EB_X,16777217.01,N\n"...)
+	pp := []DecimalPrice{}
+
+	err := Unmarshal(data, &pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Marshal(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(data, out) != true {
+		t.Error("wrong results")
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data := loadData()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		pp := []Price{}
+
+		err := Unmarshal(data, &pp)
+		if err != nil {
+			b.Fatal(err)
+		}
 	}
+}
 
-	b.Logf("Unmarshal %d rows", len(pp))
-	b.Logf("Marshaled %d bytes", len(out))
+func BenchmarkMarshal(b *testing.B) {
+	pp := []Price{}
+
+	err := Unmarshal(loadData(), &pp)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := Marshal(pp)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkUnmarshalReuse is BenchmarkUnmarshal with the ReuseRecord option
+func BenchmarkUnmarshalReuse(b *testing.B) {
+	data := loadData()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		pp := []Price{}
+
+		err := Unmarshal(data, &pp, ReuseRecord())
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalDecimal(b *testing.B) {
+	data := loadData()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		pp := []DecimalPrice{}
+
+		err := Unmarshal(data, &pp)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,217 @@
+package csv
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// Arbitrary precision columns are decoded to and encoded from *big.Int,
+// *big.Float and *big.Rat fields. The number format tags apply to them as
+// they do to ints and floats.
+
+var (
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	bigFloatType = reflect.TypeOf((*big.Float)(nil))
+	bigRatType   = reflect.TypeOf((*big.Rat)(nil))
+)
+
+// bigFloatTags reads the bits and rounding tags of a *big.Float field. bits is
+// the mantissa precision, which is 64 when it is not set, and rounding is the
+// name of a big.RoundingMode such as "ToZero".
+func bigFloatTags(st reflect.StructTag) (uint, big.RoundingMode, error) {
+	var prec uint64
+	mode := big.ToNearestEven
+
+	if b := st.Get("bits"); b != "" {
+		n, err := strconv.ParseUint(b, 10, 32)
+		if err != nil {
+			return 0, mode, fmt.Errorf("invalid bits tag %q", b)
+		}
+		prec = n
+	}
+
+	if r := st.Get("rounding"); r != "" {
+		for m := big.ToNearestEven; ; m++ {
+			if m.String() == r {
+				mode = m
+				break
+			}
+			if m == big.ToPositiveInf {
+				return 0, mode, fmt.Errorf("invalid rounding tag %q", r)
+			}
+		}
+	}
+
+	return uint(prec), mode, nil
+}
+
+// assignBigDecoder sets the decoder for a math/big field, reporting if the
+// field is one. An empty cell leaves the field nil, as Marshal writes it.
+func (cf *cfield) assignBigDecoder() bool {
+	switch cf.structField.Type {
+	case bigIntType:
+		cf.decoder = cf.numeric(false, cf.nilWhenEmpty(cf.decodeBigInt))
+	case bigRatType:
+		cf.decoder = cf.numeric(true, cf.nilWhenEmpty(cf.decodeBigRat))
+	case bigFloatType:
+		prec, mode, err := bigFloatTags(cf.structField.Tag)
+		if err != nil {
+			cf.decoder = func(reflect.Value, *Row) error {
+				return err
+			}
+			return true
+		}
+		cf.decoder = cf.numeric(true, cf.nilWhenEmpty(cf.decodeBigFloat(prec, mode)))
+	default:
+		return false
+	}
+
+	return true
+}
+
+// nilWhenEmpty sets a pointer field to nil for an empty cell and otherwise
+// decodes it with d.
+func (cf *cfield) nilWhenEmpty(d decoderFn) decoderFn {
+	return func(cell reflect.Value, row *Row) error {
+		if row.At(cf.colIndex) == "" {
+			cell.SetZero()
+			return nil
+		}
+
+		return d(cell, row)
+	}
+}
+
+func (cf *cfield) decodeBigInt(cell reflect.Value, row *Row) error {
+	val := cf.number.parse(row.At(cf.colIndex))
+	n, ok := new(big.Int).SetString(val, 10)
+
+	if !ok {
+		return fmt.Errorf("invalid integer %q", val)
+	}
+
+	cell.Set(reflect.ValueOf(n))
+	return nil
+}
+
+func (cf *cfield) decodeBigRat(cell reflect.Value, row *Row) error {
+	val := cf.number.parse(row.At(cf.colIndex))
+	n, ok := new(big.Rat).SetString(val)
+
+	if !ok {
+		return fmt.Errorf("invalid rational %q", val)
+	}
+
+	cell.Set(reflect.ValueOf(n))
+	return nil
+}
+
+func (cf *cfield) decodeBigFloat(prec uint, mode big.RoundingMode) decoderFn {
+	return func(cell reflect.Value, row *Row) error {
+		val := cf.number.parse(row.At(cf.colIndex))
+		n, _, err := big.ParseFloat(val, 10, prec, mode)
+
+		if err != nil {
+			return err
+		}
+
+		cell.Set(reflect.ValueOf(n))
+		return nil
+	}
+}
+
+// bigEncoderFor returns the encoderFn for a math/big type, or nil when t is
+// not one. A nil value is written as an empty cell.
+func bigEncoderFor(t reflect.Type, st reflect.StructTag) (encoderFn, error) {
+	switch t {
+	case bigIntType:
+		return numeric(st, false, encodeBigInt)
+	case bigRatType:
+		e, err := encodeBigRat(st)
+		if err != nil {
+			return nil, err
+		}
+		return numeric(st, true, e)
+	case bigFloatType:
+		e, err := encodeBigFloat(st)
+		if err != nil {
+			return nil, err
+		}
+		return numeric(st, true, e)
+	}
+
+	return nil, nil
+}
+
+func encodeBigInt(enc *encoder, fv reflect.Value) string {
+	if fv.IsNil() {
+		return ""
+	}
+
+	return fv.Interface().(*big.Int).String()
+}
+
+// encodeBigRat writes a rational as a decimal with precision digits after the
+// point. Without the precision tag exact decimals are written in full and
+// other values as a fraction, such as 1/3.
+func encodeBigRat(st reflect.StructTag) (encoderFn, error) {
+	ff, err := floatFormatFor(st)
+
+	if err != nil {
+		return nil, err
+	}
+
+	percent := hasTagOption(st, "percent")
+
+	return func(enc *encoder, fv reflect.Value) string {
+		if fv.IsNil() {
+			return ""
+		}
+
+		r := fv.Interface().(*big.Rat)
+
+		if percent {
+			r = new(big.Rat).Mul(r, big.NewRat(100, 1))
+		}
+
+		if ff.hasPrec {
+			return r.FloatString(ff.prec)
+		}
+
+		if n, exact := r.FloatPrec(); exact {
+			return r.FloatString(n)
+		}
+
+		return r.RatString()
+	}, nil
+}
+
+// encodeBigFloat writes a float with the fmt and precision tags, which default
+// to the shortest 'g' format which reads back as the same value.
+func encodeBigFloat(st reflect.StructTag) (encoderFn, error) {
+	ff, err := floatFormatFor(st)
+
+	if err != nil {
+		return nil, err
+	}
+
+	percent := hasTagOption(st, "percent")
+
+	return func(enc *encoder, fv reflect.Value) string {
+		if fv.IsNil() {
+			return ""
+		}
+
+		f := fv.Interface().(*big.Float)
+
+		if percent {
+			// 7 more bits hold the product exactly
+			f = new(big.Float).SetPrec(f.Prec()+7).Mul(f, big.NewFloat(100))
+		}
+
+		d := ff.merge(defaultFloatFormat)
+		return f.Text(d.fmt, d.prec)
+	}, nil
+}
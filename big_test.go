@@ -0,0 +1,91 @@
+package csv
+
+import (
+	"math/big"
+	"testing"
+)
+
+type bigs struct {
+	ID     *big.Int
+	Amount *big.Float `bits:"128" rounding:"ToNearestAway" fmt:"f"`
+	Ratio  *big.Rat
+	Share  *big.Rat   `precision:"2" locale:"en"`
+	Rate   *big.Float `csv:",percent"`
+}
+
+func TestBigRoundTrip(t *testing.T) {
+	doc := `ID,Amount,Ratio,Share,Rate
+123456789012345678901234567890,12345678901234567890.125,1/3,"1,234.50",12.5%
+-1,0.1,2.25,0.00,0%
+`
+
+	bb := []bigs{}
+	if err := Unmarshal([]byte(doc), &bb); err != nil {
+		t.Fatal(err)
+	}
+
+	if bb[0].ID.String() != "123456789012345678901234567890" {
+		t.Errorf("incorrect int %s", bb[0].ID)
+	}
+
+	if bb[0].Amount.Prec() != 128 || bb[0].Amount.Mode() != big.ToNearestAway {
+		t.Errorf("incorrect float precision %d %s", bb[0].Amount.Prec(), bb[0].Amount.Mode())
+	}
+
+	if bb[0].Ratio.Cmp(big.NewRat(1, 3)) != 0 {
+		t.Errorf("incorrect rational %s", bb[0].Ratio)
+	}
+
+	if r, _ := bb[0].Rate.Float64(); r != 0.125 {
+		t.Errorf("incorrect percent %v", r)
+	}
+
+	out, err := Marshal(bb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != doc {
+		t.Errorf("incorrect output\n%s", out)
+	}
+}
+
+func TestBigNil(t *testing.T) {
+	out, err := Marshal([]bigs{{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "ID,Amount,Ratio,Share,Rate\n,,,,\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	back := []bigs{}
+	if err := Unmarshal(out, &back); err != nil || len(back) != 1 || back[0] != (bigs{}) {
+		t.Errorf("nil values did not round trip %+v %v", back, err)
+	}
+
+	type bad struct {
+		F *big.Float `rounding:"Sideways"`
+	}
+
+	if err := Unmarshal([]byte("F\n1"), &[]bad{}); err == nil {
+		t.Error("no error for an invalid rounding tag")
+	}
+
+	type badRat struct {
+		R *big.Rat `precision:"x"`
+	}
+
+	if _, err := Marshal([]badRat{{}}); err == nil {
+		t.Error("no error for an invalid precision tag")
+	}
+
+	type badFloat struct {
+		F *big.Float `fmt:"z"`
+	}
+
+	if _, err := Marshal([]badFloat{{}}); err == nil {
+		t.Error("no error for an invalid fmt tag")
+	}
+}
@@ -6,18 +6,24 @@ import (
 	"strconv"
 )
 
-type decoderFn func(*reflect.Value, *Row) error
+type decoderFn func(reflect.Value, *Row) error
 
 // maps a CSV column Name and index to a StructField
 type cfield struct {
 	colIndex    int
+	index       []int // the field's index path in the struct
 	structField *reflect.StructField
 	decoder     decoderFn
+	boolTokens  [2]string    // the true and false tags of a bool field
+	number      numberFormat // the separators of a numeric field
+	rules       []rule       // the validation rules checked after decoding
+	field       Field        // the descriptor given to a FieldUnmarshaler
 }
 
 func newCfield(index int, sf *reflect.StructField) cfield {
 	cf := cfield{
 		colIndex:    index,
+		index:       sf.Index,
 		structField: sf,
 	}
 
@@ -26,6 +32,24 @@ func newCfield(index int, sf *reflect.StructField) cfield {
 	return cf
 }
 
+// assign picks the field's decoder, preferring a FieldUnmarshaler then an
+// Unmarshaler to the decoder of its kind. A pointer to either is decoded by
+// decodePointer, so an empty cell leaves it nil.
+func (cf *cfield) assign(f Field) {
+	t := cf.structField.Type
+	cf.field = f
+
+	if t.Kind() == reflect.Ptr && (t.Implements(fieldUnmarshalerType) || t.Implements(unmarshalerType)) {
+		cf.decoder = cf.decodePointer()
+	} else if code, err := impsUnmarshaller(t, new(FieldUnmarshaler)); err == nil {
+		cf.assignFieldUnmarshaller(code, f)
+	} else if code, err := impsUnmarshaller(t, new(Unmarshaler)); err == nil {
+		cf.assignUnmarshaller(code)
+	} else {
+		cf.assignDecoder()
+	}
+}
+
 func (cf *cfield) assignUnmarshaller(code int) {
 	if code == impsPtr {
 		cf.decoder = cf.unmarshalPointer
@@ -34,7 +58,7 @@ func (cf *cfield) assignUnmarshaller(code int) {
 	}
 }
 
-func (cf *cfield) unmarshalPointer(cell *reflect.Value, row *Row) error {
+func (cf *cfield) unmarshalPointer(cell reflect.Value, row *Row) error {
 	val := row.At(cf.colIndex)
 	m := cell.Addr().Interface().(Unmarshaler)
 	err := m.UnmarshalCSV(val, row)
@@ -42,7 +66,7 @@ func (cf *cfield) unmarshalPointer(cell *reflect.Value, row *Row) error {
 	return err
 }
 
-func (cf *cfield) unmarshalValue(cell *reflect.Value, row *Row) error {
+func (cf *cfield) unmarshalValue(cell reflect.Value, row *Row) error {
 	val := row.At(cf.colIndex)
 	m := cell.Interface().(Unmarshaler)
 	err := m.UnmarshalCSV(val, row)
@@ -50,45 +74,101 @@ func (cf *cfield) unmarshalValue(cell *reflect.Value, row *Row) error {
 }
 
 func (cf *cfield) assignDecoder() {
+	if cf.assignBigDecoder() {
+		return
+	}
+
+	if cf.structField.Type == timeType {
+		cf.decoder = cf.decodeTime(timeLayout(cf.structField.Tag))
+		return
+	}
+
 	switch cf.structField.Type.Kind() {
 	case reflect.String:
 		cf.decoder = cf.decodeString
 	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
-		cf.decoder = cf.decodeInt
+		cf.decoder = cf.numeric(false, cf.decodeInt)
 	case reflect.Float32:
-		cf.decoder = cf.decodeFloat(32)
+		cf.decoder = cf.numeric(true, cf.decodeFloat(32))
 	case reflect.Float64:
-		cf.decoder = cf.decodeFloat(64)
+		cf.decoder = cf.numeric(true, cf.decodeFloat(64))
+	case reflect.Complex64:
+		cf.decoder = cf.decodeComplex(64)
+	case reflect.Complex128:
+		cf.decoder = cf.decodeComplex(128)
 	case reflect.Bool:
+		cf.boolTokens = [2]string{cf.structField.Tag.Get("true"), cf.structField.Tag.Get("false")}
 		cf.decoder = cf.decodeBool
+	case reflect.Ptr:
+		cf.decoder = cf.decodePointer()
 	default:
 		cf.decoder = cf.ignoreValue
 	}
 }
 
-func (cf *cfield) decodeBool(cell *reflect.Value, row *Row) error {
-	val := row.At(cf.colIndex)
-	var bv bool
+// decodePointer decodes a non-empty cell into a new value of the pointer's
+// element type. An empty cell is nil.
+func (cf *cfield) decodePointer() decoderFn {
+	sf := *cf.structField
+	sf.Type = sf.Type.Elem()
 
-	bt := cf.structField.Tag.Get("true")
-	bf := cf.structField.Tag.Get("false")
+	elem := *cf
+	elem.structField = &sf
+	elem.assign(cf.field)
 
-	switch val {
-	case bt:
-		bv = true
-	case bf:
-		bv = false
-	default:
-		bv = true
+	return func(cell reflect.Value, row *Row) error {
+		if row.At(cf.colIndex) == "" {
+			cell.SetZero()
+			return nil
+		}
+
+		p := reflect.New(sf.Type)
+
+		if err := elem.decoder(p.Elem(), row); err != nil {
+			return err
+		}
+
+		cell.Set(p)
+		return nil
 	}
+}
 
-	cell.SetBool(bv)
+// numeric reads the number format tags for the decoder d. An invalid tag is
+// reported by every call of the returned decoder.
+func (cf *cfield) numeric(float bool, d decoderFn) decoderFn {
+	nf, err := numberFormatFor(cf.structField.Tag, float)
+
+	if err != nil {
+		return func(reflect.Value, *Row) error {
+			return err
+		}
+	}
+
+	cf.number = nf
+
+	return d
+}
+
+func (cf *cfield) decodeBool(cell reflect.Value, row *Row) error {
+	cell.SetBool(boolValue(row.At(cf.colIndex), cf.boolTokens))
 
 	return nil
 }
 
-func (cf *cfield) decodeInt(cell *reflect.Value, row *Row) error {
-	val := row.At(cf.colIndex)
+// boolValue is false only when val is the false token and not the true token.
+func boolValue(val string, tokens [2]string) bool {
+	switch val {
+	case tokens[0]:
+		return true
+	case tokens[1]:
+		return false
+	default:
+		return true
+	}
+}
+
+func (cf *cfield) decodeInt(cell reflect.Value, row *Row) error {
+	val := cf.number.parse(row.At(cf.colIndex))
 	i, e := strconv.Atoi(val)
 
 	if e != nil {
@@ -99,7 +179,7 @@ func (cf *cfield) decodeInt(cell *reflect.Value, row *Row) error {
 	return nil
 }
 
-func (cf *cfield) decodeString(cell *reflect.Value, row *Row) error {
+func (cf *cfield) decodeString(cell reflect.Value, row *Row) error {
 	val := row.At(cf.colIndex)
 	cell.SetString(val)
 
@@ -107,8 +187,8 @@ func (cf *cfield) decodeString(cell *reflect.Value, row *Row) error {
 }
 
 func (cf *cfield) decodeFloat(bit int) decoderFn {
-	return func(cell *reflect.Value, row *Row) error {
-		val := row.At(cf.colIndex)
+	return func(cell reflect.Value, row *Row) error {
+		val := cf.number.parse(row.At(cf.colIndex))
 		n, err := strconv.ParseFloat(val, bit)
 
 		if err != nil {
@@ -121,13 +201,75 @@ func (cf *cfield) decodeFloat(bit int) decoderFn {
 	}
 }
 
+// decodeComplex reads the forms written by Marshal, such as (+1+2i), along
+// with anything else strconv.ParseComplex accepts.
+func (cf *cfield) decodeComplex(bit int) decoderFn {
+	return func(cell reflect.Value, row *Row) error {
+		val := row.At(cf.colIndex)
+		c, err := strconv.ParseComplex(val, bit)
+
+		if err != nil {
+			return err
+		}
+
+		cell.SetComplex(c)
+
+		return nil
+	}
+}
+
+// assignMeta sets the decoder of a line, raw or offset field.
+func (cf *cfield) assignMeta(meta string) {
+	t := cf.structField.Type
+
+	switch {
+	case meta == "raw" && (t == recordType || t == rowType):
+		cf.decoder = cf.decodeRaw
+	case meta != "raw" && t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		cf.decoder = cf.decodePosition(meta == "line")
+	default:
+		err := fmt.Errorf("the %s option can not be used with %s", meta, t)
+		cf.decoder = func(reflect.Value, *Row) error {
+			return err
+		}
+	}
+}
+
+// decodeRaw copies the record, which may be reused by the csv.Reader.
+func (cf *cfield) decodeRaw(cell reflect.Value, row *Row) error {
+	data := append([]string(nil), row.Data...)
+
+	if cell.Type() == recordType {
+		cell.Set(reflect.ValueOf(data))
+		return nil
+	}
+
+	r := *row
+	r.Data = data
+	cell.Set(reflect.ValueOf(r))
+
+	return nil
+}
+
+func (cf *cfield) decodePosition(line bool) decoderFn {
+	return func(cell reflect.Value, row *Row) error {
+		if line {
+			cell.SetInt(int64(row.Line))
+		} else {
+			cell.SetInt(row.Offset)
+		}
+
+		return nil
+	}
+}
+
 // ignoreValue does nothing. This is for unsupported types.
-func (cf *cfield) ignoreValue(cell *reflect.Value, row *Row) error {
+func (cf *cfield) ignoreValue(cell reflect.Value, row *Row) error {
 	return nil
 }
 
 // unassignedDecoder is the default decoder.  It returns an error since it should
 // have been assigned.
-func (cf *cfield) unassignedDecoder(cell *reflect.Value, row *Row) error {
+func (cf *cfield) unassignedDecoder(cell reflect.Value, row *Row) error {
 	return fmt.Errorf("no decoder for %v", cf.structField.Name)
 }
@@ -0,0 +1,255 @@
+package csv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Charset is the character encoding of a document. Documents are read and
+// written in UTF-8 unless WithCharset sets another.
+type Charset int
+
+const (
+	UTF8        Charset = iota
+	UTF16               // UTF-16 with a byte order mark, little endian when there is none
+	UTF16LE             // UTF-16 little endian
+	UTF16BE             // UTF-16 big endian
+	Latin1              // ISO-8859-1
+	Windows1252         // Windows code page 1252, Latin-1 with printable characters for 0x80 to 0x9F
+)
+
+func (c Charset) String() string {
+	switch c {
+	case UTF8:
+		return "UTF-8"
+	case UTF16:
+		return "UTF-16"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case Latin1:
+		return "ISO-8859-1"
+	case Windows1252:
+		return "Windows-1252"
+	}
+
+	return fmt.Sprintf("Charset(%d)", int(c))
+}
+
+// windows1252 maps the bytes 0x80 to 0x9F to runes. The bytes the code page
+// leaves undefined map to the control characters Latin-1 has there.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// WithCharset reads and writes documents in the Charset c, transcoding to and
+// from the UTF-8 the fields hold. A UTF-16 byte order mark is skipped when
+// reading and UTF16 writes one. Runes Latin-1 or Windows-1252 can not hold
+// stop writing with an error, except the byte order mark of a Dialect, which
+// is left out. Row offsets count the bytes of the UTF-8 text.
+func WithCharset(c Charset) Option {
+	return func(o *options) {
+		o.charset = c
+	}
+}
+
+// decodeCharset returns r transcoded to UTF-8.
+func (o *options) decodeCharset(r io.Reader) io.Reader {
+	if o.charset == UTF8 {
+		return r
+	}
+
+	br := bufio.NewReader(r)
+	d := &charsetReader{r: br, charset: o.charset}
+
+	if b, err := br.Peek(2); err == nil && o.charset <= UTF16BE {
+		switch {
+		case b[0] == 0xFF && b[1] == 0xFE && o.charset != UTF16BE:
+			d.charset = UTF16LE
+			br.Discard(2)
+		case b[0] == 0xFE && b[1] == 0xFF && o.charset != UTF16LE:
+			d.charset = UTF16BE
+			br.Discard(2)
+		}
+	}
+
+	if d.charset == UTF16 {
+		d.charset = UTF16LE
+	}
+
+	return d
+}
+
+// encodeCharset returns w transcoding UTF-8 to the Charset.
+func (o *options) encodeCharset(w io.Writer) io.Writer {
+	if o.charset == UTF8 {
+		return w
+	}
+
+	return &charsetWriter{w: w, charset: o.charset}
+}
+
+// charsetReader reads UTF-8 from a document in another Charset.
+type charsetReader struct {
+	r       *bufio.Reader
+	charset Charset
+	buf     []byte // decoded bytes not yet read
+	err     error  // the error which ended the input
+}
+
+func (d *charsetReader) Read(p []byte) (int, error) {
+	for len(d.buf) < len(p) && d.err == nil {
+		var r rune
+		r, d.err = d.next()
+
+		if d.err == nil {
+			d.buf = utf8.AppendRune(d.buf, r)
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = append(d.buf[:0], d.buf[n:]...)
+
+	if n == 0 {
+		return 0, d.err
+	}
+
+	return n, nil
+}
+
+// next decodes one rune. Invalid input, such as an unpaired surrogate or an
+// odd last byte, decodes as utf8.RuneError.
+func (d *charsetReader) next() (rune, error) {
+	switch d.charset {
+	case Latin1, Windows1252:
+		b, err := d.r.ReadByte()
+
+		if err != nil {
+			return 0, err
+		}
+
+		if d.charset == Windows1252 && b >= 0x80 && b <= 0x9F {
+			return windows1252[b-0x80], nil
+		}
+
+		return rune(b), nil
+	}
+
+	u, err := d.unit()
+
+	switch {
+	case err == io.ErrUnexpectedEOF:
+		return utf8.RuneError, nil
+	case err != nil:
+		return 0, err
+	case !utf16.IsSurrogate(u):
+		return u, nil
+	}
+
+	if b, err := d.r.Peek(2); err == nil {
+		if r := utf16.DecodeRune(u, d.order(b)); r != utf8.RuneError {
+			d.r.Discard(2)
+			return r, nil
+		}
+	}
+
+	return utf8.RuneError, nil
+}
+
+// unit reads a UTF-16 code unit.
+func (d *charsetReader) unit() (rune, error) {
+	var b [2]byte
+
+	if _, err := io.ReadFull(d.r, b[:]); err != nil {
+		return 0, err
+	}
+
+	return d.order(b[:]), nil
+}
+
+func (d *charsetReader) order(b []byte) rune {
+	if d.charset == UTF16BE {
+		return rune(b[0])<<8 | rune(b[1])
+	}
+
+	return rune(b[1])<<8 | rune(b[0])
+}
+
+// charsetWriter writes UTF-8 to a document in another Charset.
+type charsetWriter struct {
+	w       io.Writer
+	charset Charset
+	part    []byte // the start of a rune cut short by the last Write
+	buf     []byte // reused for the transcoded bytes
+}
+
+func (e *charsetWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if len(e.part) > 0 {
+		p = append(e.part, p...)
+		e.part = nil
+	}
+
+	e.buf = e.buf[:0]
+
+	for len(p) > 0 {
+		if !utf8.FullRune(p) {
+			e.part = append([]byte(nil), p...)
+			break
+		}
+
+		r, size := utf8.DecodeRune(p)
+		p = p[size:]
+
+		var err error
+		if e.buf, err = e.appendRune(e.buf, r); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := e.w.Write(e.buf); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (e *charsetWriter) appendRune(b []byte, r rune) ([]byte, error) {
+	switch e.charset {
+	case UTF16, UTF16LE:
+		for _, u := range utf16.AppendRune(nil, r) {
+			b = append(b, byte(u), byte(u>>8))
+		}
+		return b, nil
+	case UTF16BE:
+		for _, u := range utf16.AppendRune(nil, r) {
+			b = append(b, byte(u>>8), byte(u))
+		}
+		return b, nil
+	}
+
+	switch {
+	case r == '\uFEFF':
+		return b, nil
+	case r < 0x80 || r > 0x9F && r < 0x100:
+		return append(b, byte(r)), nil
+	case e.charset == Latin1:
+		if r < 0x100 {
+			return append(b, byte(r)), nil
+		}
+	default:
+		for i, c := range windows1252 {
+			if c == r {
+				return append(b, byte(0x80+i)), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("csv: %q can not be written in %s", r, e.charset)
+}
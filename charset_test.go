@@ -0,0 +1,97 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCharsetRoundTrip(t *testing.T) {
+	type P struct {
+		Name  string
+		Price float64
+	}
+
+	pp := []P{{"Café", 1.5}, {"naïve, “quoted”", 2}}
+
+	tests := []struct {
+		charset Charset
+		start   string
+	}{
+		{UTF16, "\xFF\xFEN\x00"},
+		{UTF16LE, "N\x00a\x00"},
+		{UTF16BE, "\x00N\x00a"},
+		{Windows1252, "Name,Price\nCaf\xE9"},
+	}
+
+	for _, test := range tests {
+		out, err := Marshal(pp, WithCharset(test.charset))
+		if err != nil {
+			t.Fatalf("%s: %v", test.charset, err)
+		}
+
+		if !strings.HasPrefix(string(out), test.start) {
+			t.Errorf("%s: incorrect output %q", test.charset, out)
+		}
+
+		back := []P{}
+		if err := Unmarshal(out, &back, WithCharset(test.charset)); err != nil {
+			t.Fatalf("%s: %v", test.charset, err)
+		}
+
+		if len(back) != 2 || back[0] != pp[0] || back[1] != pp[1] {
+			t.Errorf("%s: incorrect rows %+v", test.charset, back)
+		}
+	}
+
+	if _, err := Marshal(pp, WithCharset(Latin1)); err == nil {
+		t.Error("no error writing “ in Latin-1")
+	}
+}
+
+func TestCharsetDecode(t *testing.T) {
+	tests := []struct {
+		charset Charset
+		doc     string
+		val     string
+	}{
+		{Latin1, "A\nd\xE9j\xE0 \x80\n", "déjà \u0080"},
+		{Windows1252, "A\n\x80 \x93x\x94 \x81\n", "€ “x” \u0081"},
+		{UTF16, "\xFE\xFF\x00A\x00\n\xD8\x3D\xDE\x00\x00\n", "😀"},
+		{UTF16, "A\x00\n\x00=\xD8\x00\xDE\n\x00", "😀"},
+		{UTF16BE, "\xFE\xFF\x00A\x00\n\x00x\xD8\x00\x00\n", "x�"},
+	}
+
+	for _, test := range tests {
+		rr, err := ReadAll[Row](strings.NewReader(test.doc), WithCharset(test.charset))
+
+		if err != nil || len(rr) != 1 || rr[0].At(0) != test.val {
+			t.Errorf("%s: expected %q got %v %v", test.charset, test.val, rr, err)
+		}
+	}
+}
+
+func TestCharsetDialect(t *testing.T) {
+	type Z struct {
+		Zip string
+	}
+
+	var b bytes.Buffer
+	if err := WriteAll(&b, []Z{{"007"}}, WithDialect(Excel), WithCharset(UTF16LE)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.Bytes()
+	if !bytes.HasPrefix(out, []byte("\xFF\xFEZ\x00")) {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	zz := []Z{}
+	if err := Unmarshal(out, &zz, AutoDetect(), WithCharset(UTF16)); err != nil || len(zz) != 1 || zz[0].Zip != "007" {
+		t.Errorf("incorrect rows %+v %v", zz, err)
+	}
+
+	if _, err := Marshal([]Z{{"1"}}, WithDialect(Excel), WithCharset(Latin1)); err != nil {
+		t.Errorf("the byte order mark was not left out: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Command csv2struct writes a Go struct type for the records of a CSV file.
+//
+// The type is inferred from the header and a sample of the records by
+// csv.InferStruct, with the tags Unmarshal needs to decode the file.
+//
+// Usage:
+//
+//	csv2struct [-name Record] [-n 100] [-comma ,] [-package p] [-output file.go] [file.csv]
+//
+// The CSV is read from standard input when no file is given, and the type is
+// written to standard output unless -output is set. With -package a complete
+// Go file is written.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/jweir/csv"
+)
+
+func main() {
+	name := flag.String("name", "Record", "name of the struct type")
+	n := flag.Int("n", 100, "number of records to sample, 0 for all")
+	comma := flag.String("comma", ",", "field delimiter")
+	pkg := flag.String("package", "", "package name; when set a complete file is written")
+	output := flag.String("output", "", "output file name; default standard output")
+	flag.Parse()
+
+	if flag.NArg() > 1 || utf8.RuneCountInString(*comma) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: csv2struct [-name T] [-n rows] [-comma c] [-package p] [-output file] [file.csv]")
+		os.Exit(2)
+	}
+
+	var in io.Reader = os.Stdin
+
+	if flag.NArg() == 1 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	r, _ := utf8.DecodeRuneInString(*comma)
+	src, err := csv.InferStruct(in, *name, *n, csv.Comma(r))
+
+	if err != nil {
+		fail(err)
+	}
+
+	if *pkg != "" {
+		if src, err = file(*pkg, src); err != nil {
+			fail(err)
+		}
+	}
+
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fail(err)
+	}
+}
+
+// file returns a Go file of package pkg declaring the type in src.
+func file(pkg string, src []byte) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+
+	if bytes.Contains(src, []byte("time.Time")) {
+		b.WriteString("import \"time\"\n\n")
+	}
+
+	b.Write(src)
+
+	return format.Source(b.Bytes())
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "csv2struct:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// field is a struct field written to or read from a column
+type field struct {
+	name    string // the Go field name
+	header  string // the column name
+	tag     reflect.StructTag
+	options map[string]bool // the options of the csv tag
+	typ     types.Type
+	read    bool   // exported fields are decoded, as Unmarshal only sets those
+	meta    string // the line, raw or offset option, set from the record
+}
+
+// generator accumulates the source for one file
+type generator struct {
+	pkg     *types.Package
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// Generate returns the source of the CSV methods for the named struct types
+// declared in the file src.
+func Generate(src string, typeNames []string) ([]byte, error) {
+	pkg, err := load(src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{pkg: pkg, imports: map[string]bool{}}
+
+	for _, name := range typeNames {
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			return nil, fmt.Errorf("type %s not found", name)
+		}
+
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a struct", name)
+		}
+
+		if err := g.generate(name, fields(st)); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+
+	// the standard library first, then the other packages
+	var std, other []string
+	for path := range g.imports {
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	fmt.Fprintf(&out, "// Code generated by csvgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg.Name())
+	for _, path := range std {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString("\n")
+	for _, path := range other {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(g.buf.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// load parses and type checks the files in src's directory which share its
+// package. Type errors are ignored so a package can be generated before the
+// methods it is missing exist.
+func load(src string) (*types.Package, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, src, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*ast.File{f}
+	paths, _ := filepath.Glob(filepath.Join(filepath.Dir(src), "*.go"))
+
+	for _, path := range paths {
+		if filepath.Clean(path) == filepath.Clean(src) {
+			continue
+		}
+
+		pf, err := parser.ParseFile(fset, path, nil, 0)
+		if err == nil && pf.Name.Name == f.Name.Name {
+			files = append(files, pf)
+		}
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+
+	pkg, _ := conf.Check(f.Name.Name, fset, files, nil)
+
+	return pkg, nil
+}
+
+// fields returns the columns of st, following the csv package's
+// fieldHeaderName.
+func fields(st *types.Struct) []field {
+	var out []field
+
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		h, opts := splitTag(tag)
+
+		if h == "-" {
+			continue
+		}
+
+		if h == "" {
+			h = v.Name()
+		}
+
+		options := map[string]bool{}
+		for _, o := range opts {
+			options[o] = true
+		}
+
+		f := field{
+			name:    v.Name(),
+			header:  h,
+			tag:     tag,
+			options: options,
+			typ:     v.Type(),
+			read:    v.Exported(),
+		}
+
+		for _, o := range []string{"line", "raw", "offset"} {
+			if options[o] {
+				f.meta = o
+				break
+			}
+		}
+
+		out = append(out, f)
+	}
+
+	return out
+}
+
+// tagOptions are the options which may follow the name in a csv tag
+var tagOptions = []string{"accounting", "percent", "line", "raw", "offset"}
+
+// splitTag returns the name and options of the csv tag, following the csv
+// package's splitTag.
+func splitTag(st reflect.StructTag) (string, []string) {
+	tag := st.Get("csv")
+	parts := strings.Split(tag, ",")
+	n := len(parts)
+
+	for n > 1 && slices.Contains(tagOptions, parts[n-1]) {
+		n--
+	}
+
+	if n == len(parts) {
+		return tag, nil
+	}
+
+	return strings.Join(parts[:n], ","), parts[n:]
+}
+
+// unsupportedTags are honoured by the reflective encoder and decoder but not by
+// generated code.
+var unsupportedTags = []string{"locale", "decimal", "group", "currency", "validate", "oneof", "regex", "len"}
+
+// unsupportedOptions are csv tag options which generated code does not honour
+var unsupportedOptions = []string{"accounting", "percent"}
+
+func (g *generator) generate(name string, ff []field) error {
+	g.imports["github.com/jweir/csv"] = true
+
+	for _, f := range ff {
+		for _, t := range unsupportedTags {
+			if _, ok := f.tag.Lookup(t); ok {
+				return fmt.Errorf("%s.%s: the %s tag is not supported by csvgen", name, f.name, t)
+			}
+		}
+
+		if hasMethod(f.typ, "CSVColumns", true) {
+			return fmt.Errorf("%s.%s: ColumnsMarshaler fields are not supported by csvgen", name, f.name)
+		}
+
+		if hasMethod(f.typ, "UnmarshalCSVField", true) || hasMethod(f.typ, "MarshalCSVField", true) {
+			return fmt.Errorf("%s.%s: FieldUnmarshaler and FieldMarshaler fields are not supported by csvgen", name, f.name)
+		}
+
+		for _, o := range unsupportedOptions {
+			if f.options[o] {
+				return fmt.Errorf("%s.%s: the %s option is not supported by csvgen", name, f.name, o)
+			}
+		}
+	}
+
+	g.printf("\n// CSVHeader returns the column names of %s.\n", name)
+	g.printf("func (%s) CSVHeader() []string {\n\treturn []string{", name)
+	for i, f := range columns(ff) {
+		if i > 0 {
+			g.printf(", ")
+		}
+		g.printf("%q", f.header)
+	}
+	g.printf("}\n}\n")
+
+	g.printf("\n// MarshalCSVRow appends the columns of t to row.\n")
+	g.printf("func (t %s) MarshalCSVRow(row []string) ([]string, error) {\n", name)
+	for _, f := range columns(ff) {
+		if err := g.encodeField(f); err != nil {
+			return fmt.Errorf("%s.%s: %v", name, f.name, err)
+		}
+	}
+	g.printf("\treturn row, nil\n}\n")
+
+	g.printf("\n// UnmarshalCSVRow sets the fields of t from the matching columns of row.\n")
+	g.printf("func (t *%s) UnmarshalCSVRow(row *csv.Row) error {\n", name)
+	for _, f := range ff {
+		switch {
+		case !f.read:
+		case f.meta != "":
+			if err := g.decodeMeta(f); err != nil {
+				return fmt.Errorf("%s.%s: %v", name, f.name, err)
+			}
+		default:
+			g.decodeField(f)
+		}
+	}
+	g.printf("\treturn nil\n}\n")
+
+	return nil
+}
+
+// columns returns the fields written as columns, leaving out those set from
+// the record.
+func columns(ff []field) []field {
+	var out []field
+
+	for _, f := range ff {
+		if f.meta == "" {
+			out = append(out, f)
+		}
+	}
+
+	return out
+}
+
+// decodeMeta mirrors cfield.assignMeta for a line, raw or offset field.
+func (g *generator) decodeMeta(f field) error {
+	v := "t." + f.name
+	u := f.typ.Underlying()
+
+	switch {
+	case f.meta == "raw" && types.TypeString(f.typ, nil) == "[]string":
+		g.printf("\t%s = append([]string(nil), row.Data...)\n", v)
+	case f.meta == "raw" && types.TypeString(f.typ, nil) == "github.com/jweir/csv.Row":
+		g.printf("\t%s = *row\n\t%s.Data = append([]string(nil), row.Data...)\n", v, v)
+	case f.meta == "line" && isInteger(u):
+		g.printf("\t%s = %s\n", v, g.convert(f.typ, "row.Line", types.Typ[types.Int]))
+	case f.meta == "offset" && isInteger(u):
+		g.printf("\t%s = %s\n", v, g.convert(f.typ, "row.Offset", types.Typ[types.Int64]))
+	default:
+		return fmt.Errorf("the %s option can not be used with %s", f.meta, f.typ)
+	}
+
+	return nil
+}
+
+// isInteger reports if t is a signed integer type
+func isInteger(t types.Type) bool {
+	b, ok := t.(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0 && b.Info()&types.IsUnsigned == 0
+}
+
+// hasMethod reports if t, or *t when ptr is set, has the named method
+func hasMethod(t types.Type, name string, ptr bool) bool {
+	if ptr {
+		t = types.NewPointer(t)
+	}
+
+	return types.NewMethodSet(t).Lookup(nil, name) != nil
+}
+
+// encodeField mirrors encoder.encodeCol, which switches on the field's kind
+// and only uses a Marshaler for structs and interfaces.
+func (g *generator) encodeField(f field) error {
+	v := "t." + f.name
+
+	if types.TypeString(f.typ, nil) == "time.Time" {
+		return fmt.Errorf("time.Time fields are not supported by csvgen")
+	}
+
+	switch u := f.typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			g.printf("\trow = append(row, %s)\n", g.convert(types.Typ[types.String], v, f.typ))
+		case u.Info()&types.IsBoolean != 0:
+			tv, fv := "true", "false"
+			if s := f.tag.Get("true"); s != "" {
+				tv = s
+			}
+			if s := f.tag.Get("false"); s != "" {
+				fv = s
+			}
+			g.printf("\tif %s {\n\t\trow = append(row, %q)\n\t} else {\n\t\trow = append(row, %q)\n\t}\n", v, tv, fv)
+		case u.Info()&types.IsUnsigned != 0:
+			g.imports["strconv"] = true
+			g.printf("\trow = append(row, strconv.FormatUint(uint64(%s), 10))\n", v)
+		case u.Info()&types.IsInteger != 0:
+			g.imports["strconv"] = true
+			g.printf("\trow = append(row, strconv.FormatInt(int64(%s), 10))\n", v)
+		case u.Info()&types.IsFloat != 0:
+			return g.encodeFloat(f, v, bits(u))
+		case u.Info()&types.IsComplex != 0:
+			return g.encodeComplex(f, v, bits(u)*2)
+		default:
+			return fmt.Errorf("unsupported type %s", f.typ)
+		}
+	case *types.Struct, *types.Interface:
+		if hasMethod(f.typ, "MarshalCSV", false) {
+			g.printf("\tif b, err := %s.MarshalCSV(); err == nil {\n\t\trow = append(row, string(b))\n\t} else {\n\t\trow = append(row, \"\")\n\t}\n", v)
+		} else {
+			g.printf("\trow = append(row, \"\")\n")
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", f.typ)
+	}
+
+	return nil
+}
+
+// encodeFloat mirrors the format, fmt and precision tags of encodeFloat. The
+// FloatFormat option can not apply to generated code.
+func (g *generator) encodeFloat(f field, v string, bits int) error {
+	if verb := f.tag.Get("format"); verb != "" {
+		g.imports["fmt"] = true
+		g.printf("\trow = append(row, fmt.Sprintf(%q, float64(%s)))\n", verb, v)
+		return nil
+	}
+
+	fc, prec, _, err := floatTags(f)
+	if err != nil {
+		return err
+	}
+
+	g.imports["strconv"] = true
+	g.printf("\trow = append(row, strconv.FormatFloat(float64(%s), '%s', %d, %d))\n", v, fc, prec, bits)
+
+	return nil
+}
+
+// encodeComplex mirrors encodeComplex, which keeps the three digit default
+// unless a tag is set.
+func (g *generator) encodeComplex(f field, v string, bits int) error {
+	if verb := f.tag.Get("format"); verb != "" {
+		g.imports["fmt"] = true
+		g.printf("\trow = append(row, fmt.Sprintf(%q, complex128(%s)))\n", verb, v)
+		return nil
+	}
+
+	fc, prec, set, err := floatTags(f)
+	if err != nil {
+		return err
+	}
+
+	if !set {
+		g.imports["fmt"] = true
+		g.printf("\trow = append(row, fmt.Sprintf(\"%%+.3g\", complex128(%s)))\n", v)
+		return nil
+	}
+
+	g.imports["strconv"] = true
+	g.printf("\trow = append(row, strconv.FormatComplex(complex128(%s), '%s', %d, %d))\n", v, fc, prec, bits)
+
+	return nil
+}
+
+// floatTags reads the fmt and precision tags, which default to 'g' and -1, and
+// reports if either is set.
+func floatTags(f field) (fc string, prec int, set bool, err error) {
+	fc, prec = "g", -1
+
+	if s := f.tag.Get("fmt"); s != "" {
+		if len(s) != 1 || !strings.Contains("bgeEfGxX", s) {
+			return fc, prec, set, fmt.Errorf("invalid fmt tag %q", s)
+		}
+		fc, set = s, true
+	}
+
+	if s := f.tag.Get("precision"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fc, prec, set, fmt.Errorf("invalid precision tag %q", s)
+		}
+		prec, set = n, true
+	}
+
+	return fc, prec, set, nil
+}
+
+// decodeField mirrors cfield's decoders. Unsupported types are ignored, just
+// as cfield.ignoreValue ignores them.
+func (g *generator) decodeField(f field) {
+	v := "t." + f.name
+	open := fmt.Sprintf("\tif v, err := row.Named(%q); err == nil {\n", f.header)
+
+	if hasMethod(f.typ, "UnmarshalCSV", true) {
+		g.printf("%s\t\tif err := %s.UnmarshalCSV(v, row); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n", open, v)
+		return
+	}
+
+	u, ok := f.typ.Underlying().(*types.Basic)
+	if !ok {
+		return
+	}
+
+	switch {
+	case u.Info()&types.IsString != 0:
+		g.printf("%s\t\t%s = %s\n\t}\n", open, v, g.convert(f.typ, "v", types.Typ[types.String]))
+	case u.Info()&types.IsBoolean != 0:
+		b := fmt.Sprintf("v == %s || v != %s", strconv.Quote(f.tag.Get("true")), strconv.Quote(f.tag.Get("false")))
+		g.printf("%s\t\t%s = %s\n\t}\n", open, v, g.convert(f.typ, b, types.Typ[types.Bool]))
+	case u.Info()&types.IsUnsigned != 0:
+		// uints are not decoded by Unmarshal
+	case u.Info()&types.IsInteger != 0:
+		g.imports["strconv"] = true
+		g.printf("%s\t\tn, err := strconv.Atoi(v)\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\t%s = %s\n\t}\n", open, v, g.convert(f.typ, "n", types.Typ[types.Int]))
+	case u.Info()&types.IsFloat != 0:
+		g.imports["strconv"] = true
+		g.printf("%s\t\tn, err := strconv.ParseFloat(v, %d)\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\t%s = %s\n\t}\n", open, bits(u), v, g.convert(f.typ, "n", types.Typ[types.Float64]))
+	case u.Info()&types.IsComplex != 0:
+		g.imports["strconv"] = true
+		g.printf("%s\t\tc, err := strconv.ParseComplex(v, %d)\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\t%s = %s\n\t}\n", open, bits(u)*2, v, g.convert(f.typ, "c", types.Typ[types.Complex128]))
+	}
+}
+
+// convert returns expr, which has the type from, converted to the type to
+func (g *generator) convert(to types.Type, expr string, from types.Type) string {
+	if types.Identical(to, from) {
+		return expr
+	}
+
+	name := types.TypeString(to, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.imports[p.Path()] = true
+		return p.Name()
+	})
+
+	return name + "(" + expr + ")"
+}
+
+func bits(b *types.Basic) int {
+	switch b.Kind() {
+	case types.Float32, types.Complex64:
+		return 32
+	}
+	return 64
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestGenerate checks the committed generated test types are up to date.
+func TestGenerate(t *testing.T) {
+	out, err := Generate("../../gentypes_test.go", []string{"GenPrice", "GenMixed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("../../gentypes_csv_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out, expected) {
+		t.Errorf("generated code is out of date, run go generate\n%s", out)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	if _, err := Generate("../../gentypes_test.go", []string{"Missing"}); err == nil {
+		t.Error("no error for a missing type")
+	}
+
+	if _, err := Generate("../../gentypes_test.go", []string{"Code"}); err == nil {
+		t.Error("no error for a non struct type")
+	}
+
+	if _, err := Generate("../../gentypes_test.go", []string{"GenLocale"}); err == nil {
+		t.Error("no error for an unsupported tag")
+	}
+}
@@ -0,0 +1,56 @@
+// Command csvgen writes reflection free CSV methods for struct types.
+//
+// The generated methods implement csv.RowMarshaler and csv.RowUnmarshaler,
+// which Marshal, Unmarshal and the streaming functions use in place of
+// reflection. The csv, true and false tags are honoured exactly as the
+// reflective encoder and decoder honour them.
+//
+// Usage:
+//
+//	csvgen -type Price[,Other] [-output price_csv.go] file.go
+//
+// It is usually run with go:generate:
+//
+//	//go:generate go run github.com/jweir/csv/cmd/csvgen -type Price price.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <file>_csv.go")
+	flag.Parse()
+
+	if *typeNames == "" || flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: csvgen -type T[,T] [-output file] file.go")
+		os.Exit(2)
+	}
+
+	src := flag.Arg(0)
+	out, err := Generate(src, strings.Split(*typeNames, ","))
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "csvgen:", err)
+		os.Exit(1)
+	}
+
+	name := *output
+	if name == "" {
+		base := strings.TrimSuffix(src, ".go")
+		if strings.HasSuffix(base, "_test") {
+			name = strings.TrimSuffix(base, "_test") + "_csv_test.go"
+		} else {
+			name = base + "_csv.go"
+		}
+	}
+
+	if err := os.WriteFile(name, out, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "csvgen:", err)
+		os.Exit(1)
+	}
+}
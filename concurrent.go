@@ -0,0 +1,203 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"sync"
+)
+
+const (
+	// batchSize is the number of records given to a worker at once
+	batchSize = 512
+
+	// chunkSize is the number of rows each worker encodes at once
+	chunkSize = 4096
+)
+
+// batch is a run of consecutive records decoded by one worker
+type batch struct {
+	rows []Row
+	out  reflect.Value // the decoded rows
+	n    int           // the number of rows kept before err
+	err  error
+	errs []error // the collected row errors
+	done chan struct{}
+}
+
+// unmarshalConcurrent reads records on one goroutine and decodes them in
+// batches on dec.workers goroutines. Batches are appended to out in the order
+// they were read, stopping at the first error.
+func (dec *decoder) unmarshalConcurrent(out reflect.Value) error {
+	work := make(chan *batch, dec.workers)
+	order := make(chan *batch, dec.workers*2)
+	quit := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < dec.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range work {
+				dec.decodeBatch(b)
+				close(b.done)
+			}
+		}()
+	}
+
+	var readErr error
+
+	go func() {
+		defer close(order)
+		defer close(work)
+
+		for {
+			b, err := dec.readBatch()
+
+			if len(b.rows) > 0 {
+				select {
+				case order <- b:
+					work <- b
+				case <-quit:
+					return
+				}
+			}
+
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				return
+			}
+		}
+	}()
+
+	var err error
+
+	for b := range order {
+		<-b.done
+
+		if err != nil {
+			continue
+		}
+
+		out.Set(reflect.AppendSlice(out, b.out.Slice(0, b.n)))
+		dec.errs = append(dec.errs, b.errs...)
+
+		if b.err != nil {
+			err = b.err
+			close(quit)
+		}
+	}
+
+	wg.Wait()
+
+	if err == nil {
+		err = readErr
+	}
+
+	if err == nil {
+		err = dec.collected()
+	}
+
+	return err
+}
+
+// readBatch reads up to batchSize records.
+func (dec *decoder) readBatch() (*batch, error) {
+	b := &batch{
+		rows: make([]Row, 0, batchSize),
+		done: make(chan struct{}),
+	}
+
+	for len(b.rows) < batchSize {
+		b.rows = append(b.rows, Row{Columns: &dec.cols, index: dec.index})
+
+		if err := dec.readRow(&b.rows[len(b.rows)-1]); err != nil {
+			b.rows = b.rows[:len(b.rows)-1]
+			return b, err
+		}
+	}
+
+	return b, nil
+}
+
+// decodeBatch decodes the batch's rows until the first error which is not
+// collected. Dropped rows are overwritten by the next row.
+func (dec *decoder) decodeBatch(b *batch) {
+	b.out = reflect.MakeSlice(reflect.SliceOf(dec.Type), len(b.rows), len(b.rows))
+
+	for i := range b.rows {
+		el := b.out.Index(b.n)
+		keep, err := dec.keep(dec.decode(&b.rows[i], &el), &b.errs)
+
+		if err != nil {
+			b.err = err
+			return
+		}
+
+		if keep {
+			b.n++
+		} else {
+			el.SetZero()
+		}
+	}
+}
+
+// encodeConcurrent encodes data in rounds of one chunk per worker. Each chunk
+// is written to its own buffer with the encoder's plan and options, then the
+// buffers are written to the output in order, so the result is identical to
+// encodeAll.
+func (enc *encoder) encodeConcurrent(data reflect.Value) error {
+	n := data.Len()
+	workers := enc.opts.workers
+	bufs := make([]bytes.Buffer, workers)
+	errs := make([]error, workers)
+	encs := make([]*encoder, workers)
+
+	enc.Flush()
+
+	for start := 0; start < n; start += chunkSize * workers {
+		var wg sync.WaitGroup
+
+		for i := 0; i < workers; i++ {
+			lo := start + i*chunkSize
+			if lo >= n {
+				break
+			}
+			hi := min(lo+chunkSize, n)
+
+			wg.Add(1)
+			go func(i, lo int, chunk reflect.Value) {
+				defer wg.Done()
+
+				bufs[i].Reset()
+				ce := newEncoder(&bufs[i], enc.plan.Type, enc.opts)
+				encs[i] = ce
+				errs[i] = ce.encodeAll(chunk, lo)
+				ce.Flush()
+
+				if errs[i] == nil {
+					errs[i] = ce.Error()
+				}
+			}(i, lo, data.Slice(lo, hi))
+		}
+
+		wg.Wait()
+
+		for i := 0; i < workers && start+i*chunkSize < n; i++ {
+			if errs[i] != nil {
+				return errs[i]
+			}
+
+			enc.errs = append(enc.errs, encs[i].errs...)
+
+			if _, err := enc.w.Write(bufs[i].Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
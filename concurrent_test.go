@@ -0,0 +1,114 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalConcurrent(t *testing.T) {
+	data := loadData()
+
+	serial := []Price{}
+	if err := Unmarshal(data, &serial); err != nil {
+		t.Fatal(err)
+	}
+
+	parallel := []Price{}
+	if err := Unmarshal(data, &parallel, Concurrency(4)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Error("concurrent results differ from serial results")
+	}
+}
+
+func TestUnmarshalConcurrentError(t *testing.T) {
+	var b strings.Builder
+
+	b.WriteString("Name,Age\n")
+	for i := 0; i < 3000; i++ {
+		age := fmt.Sprint(i)
+		if i == 1500 || i == 2500 {
+			age = "x"
+		}
+		fmt.Fprintf(&b, "n%d,%s\n", i, age)
+	}
+
+	gg := []gen{}
+	err := Unmarshal([]byte(b.String()), &gg, Concurrency(8))
+
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected a DecodeError got %v", err)
+	}
+
+	if de.Line != 1502 || de.Column != "Age" {
+		t.Errorf("incorrect error position %v", de)
+	}
+
+	if len(gg) != 1500 || gg[1499].Name != "n1499" {
+		t.Errorf("incorrect rows before the error %d", len(gg))
+	}
+}
+
+func BenchmarkUnmarshalConcurrent(b *testing.B) {
+	data := loadData()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		pp := []Price{}
+
+		err := Unmarshal(data, &pp, Concurrency(4))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMarshalConcurrent(t *testing.T) {
+	pp := []Price{}
+	if err := Unmarshal(loadData(), &pp); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][]Price{pp, pp[:10], pp[:chunkSize*3+1]} {
+		serial, err := Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parallel, err := Marshal(data, Concurrency(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(serial, parallel) {
+			t.Errorf("concurrent output differs for %d rows", len(data))
+		}
+	}
+}
+
+func BenchmarkMarshalConcurrent(b *testing.B) {
+	pp := []Price{}
+
+	err := Unmarshal(loadData(), &pp)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := Marshal(pp, Concurrency(4))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
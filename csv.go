@@ -4,14 +4,39 @@ package csv
 
 import (
 	"reflect"
+	"slices"
+	"strings"
 )
 
+// tagOptions are the options which may follow the name in a csv tag
+var tagOptions = []string{"accounting", "percent", "line", "raw", "offset"}
+
+// splitTag returns the name and options of the csv tag. The options are the
+// parts after the name's last comma which are all tagOptions, so a header
+// such as "Last, First" keeps its comma.
+func splitTag(st reflect.StructTag) (string, []string) {
+	tag := st.Get("csv")
+	parts := strings.Split(tag, ",")
+	n := len(parts)
+
+	for n > 1 && slices.Contains(tagOptions, parts[n-1]) {
+		n--
+	}
+
+	if n == len(parts) {
+		return tag, nil
+	}
+
+	return strings.Join(parts[:n], ","), parts[n:]
+}
+
 // fieldHeaderName returns the header name to use for the given StructField
 // This can be a user defined name (via the Tag) or a default name.
+// Options may follow the name, as in `csv:"Name,option"`.
 func fieldHeaderName(f reflect.StructField) (string, bool) {
-	h := f.Tag.Get("csv")
+	h, _ := splitTag(f.Tag)
 
-	if h == "-" {
+	if h == "-" || metaOption(f.Tag) != "" {
 		return "", false
 	}
 
@@ -22,3 +47,21 @@ func fieldHeaderName(f reflect.StructField) (string, bool) {
 
 	return h, true
 }
+
+// metaOption returns the line, raw or offset option of a field which is set
+// from the record rather than a column, or "".
+func metaOption(st reflect.StructTag) string {
+	for _, o := range []string{"line", "raw", "offset"} {
+		if hasTagOption(st, o) {
+			return o
+		}
+	}
+
+	return ""
+}
+
+// hasTagOption reports if opt is one of the options of the csv tag.
+func hasTagOption(st reflect.StructTag, opt string) bool {
+	_, opts := splitTag(st)
+	return slices.Contains(opts, opt)
+}
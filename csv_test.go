@@ -51,3 +51,100 @@ func TestHeaders(t *testing.T) {
 		t.Errorf("Incorrected headers: %v", hh)
 	}
 }
+
+func TestPlanCache(t *testing.T) {
+	x := reflect.TypeOf(simple{})
+
+	if encodePlanFor(x) != encodePlanFor(x) {
+		t.Error("encode plan was not cached")
+	}
+
+	cols := []string{"FullName", "Age"}
+	a := (&decoder{Type: x}).cfieldsFor(cols)
+	b := (&decoder{Type: x}).cfieldsFor(cols)
+
+	if len(a) != 2 || &a[0] != &b[0] {
+		t.Error("decode plan was not cached")
+	}
+}
+
+func TestPlanCacheConcurrent(t *testing.T) {
+	done := make(chan error)
+
+	for i := 0; i < 8; i++ {
+		go func() {
+			gg := []gen{}
+			err := Unmarshal([]byte(genDoc), &gg)
+			if err == nil {
+				_, err = Marshal(gg)
+			}
+			done <- err
+		}()
+	}
+
+	for i := 0; i < 8; i++ {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTagOptions(t *testing.T) {
+	type O struct {
+		Amount float64 `csv:"Total,accounting,percent"`
+		Rate   float64 `csv:",percent"`
+	}
+
+	x := reflect.TypeOf(O{})
+
+	if hh := colNames(x); fmt.Sprintf("%v", hh) != "[Total Rate]" {
+		t.Errorf("Incorrected headers: %v", hh)
+	}
+
+	f, _ := x.FieldByName("Amount")
+
+	if !hasTagOption(f.Tag, "accounting") || !hasTagOption(f.Tag, "percent") {
+		t.Error("options not found")
+	}
+
+	if hasTagOption(f.Tag, "Total") || hasTagOption(f.Tag, "line") {
+		t.Error("incorrect option found")
+	}
+
+	// a comma which is not followed by options is part of the name
+	type N struct {
+		Name string `csv:"Last, First"`
+		Code string `csv:"a,b,percent"`
+	}
+
+	out, err := Marshal([]N{{"Lovelace, Ada", "x"}})
+	if err != nil || string(out) != "\"Last, First\",\"a,b\"\n\"Lovelace, Ada\",x\n" {
+		t.Errorf("incorrect output %q %v", out, err)
+	}
+
+	nn := []N{}
+	if err := Unmarshal(out, &nn); err != nil || len(nn) != 1 || nn[0].Name != "Lovelace, Ada" {
+		t.Errorf("incorrect rows %+v %v", nn, err)
+	}
+}
+
+func TestShiftPoint(t *testing.T) {
+	tests := []struct {
+		in  string
+		n   int
+		out string
+	}{
+		{"12.5", -2, "0.125"},
+		{"0.125", 2, "12.5"},
+		{"0.5", 2, "50"},
+		{"-7", -2, "-0.07"},
+		{"1.5e-05", 2, "1.5e-03"},
+		{"100", -2, "1.00"},
+	}
+
+	for _, test := range tests {
+		if s := shiftPoint(test.in, test.n); s != test.out {
+			t.Errorf("shiftPoint(%s, %d) expected %s got %s", test.in, test.n, test.out, s)
+		}
+	}
+}
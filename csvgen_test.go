@@ -0,0 +1,116 @@
+package csv_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jweir/csv"
+)
+
+// The ref types share the fields and tags of the generated types, but not
+// their methods, so they are encoded and decoded with reflection.
+type (
+	refPrice GenPrice
+	refMixed GenMixed
+)
+
+func TestGeneratedMarshal(t *testing.T) {
+	gen := []GenMixed{
+		{"Smith, Joe", "A1", -3, 42, 1.5e+21, 12000000, 0.25, true, 90 * time.Second, 1 + 2i, 1.0000001 - 2i, Spot{"1", "2"}, "skip", 7, 0, 0, nil},
+		{Name: "Jane", Ratio: 0.25},
+	}
+
+	ref := make([]refMixed, len(gen))
+	for i, g := range gen {
+		ref[i] = refMixed(g)
+	}
+
+	a, err := csv.Marshal(gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := csv.Marshal(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(a, b) {
+		t.Errorf("generated output differs\n%s\n%s", a, b)
+	}
+}
+
+func TestGeneratedUnmarshal(t *testing.T) {
+	doc := []byte(`Full Name,Code,Count,Total,Ratio,Price,Rate,Ready,Wait,C64,Phase,Spot,Extra
+"Smith, Joe",A1,-3,42,1.5e+21,12000000.00,0.250,true,90000000000,(+1+2i),(1.0000001-2i),1 2,x
+Jane,,0,0,0.25,1.5,2,false,-5,(+0+0i),3i, ,y
+`)
+
+	gen := []GenMixed{}
+	if err := csv.Unmarshal(doc, &gen); err != nil {
+		t.Fatal(err)
+	}
+
+	ref := []refMixed{}
+	if err := csv.Unmarshal(doc, &ref); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gen) != 2 || len(gen) != len(ref) {
+		t.Fatalf("incorrect lengths %d %d", len(gen), len(ref))
+	}
+
+	for i := range gen {
+		if !reflect.DeepEqual(gen[i], GenMixed(ref[i])) {
+			t.Errorf("generated decode differs\n%+v\n%+v", gen[i], ref[i])
+		}
+	}
+}
+
+func TestGeneratedRoundTrip(t *testing.T) {
+	f, err := os.Open("testdata/ercot-dam.csv.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := []GenPrice{}
+	if err := csv.Unmarshal(data, &gen); err != nil {
+		t.Fatal(err)
+	}
+
+	ref := []refPrice{}
+	if err := csv.Unmarshal(data, &ref); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range gen {
+		if gen[i] != GenPrice(ref[i]) {
+			t.Fatalf("row %d differs %+v %+v", i, gen[i], ref[i])
+		}
+	}
+
+	out, err := csv.Marshal(gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, out) {
+		t.Error("generated round trip differs")
+	}
+}
@@ -0,0 +1,144 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxScale is the most digits a Decimal holds after the point
+const maxScale = 18
+
+var errDecimalRange = errors.New("decimal out of range")
+
+// Decimal is an exact fixed point number. It is stored as an integer count of
+// units and the number of digits after the decimal point, so "12.50" is 1250
+// units with a scale of 2.
+//
+// Decimal implements Marshaler and Unmarshaler and writes back the digits it
+// read, trailing zeros included, which makes money columns round trip exactly.
+// An empty cell is read as zero and written back empty.
+type Decimal struct {
+	units int64
+	scale uint8
+	empty bool // read from an empty cell
+}
+
+// NewDecimal returns the Decimal units / 10^scale.
+func NewDecimal(units int64, scale int) (Decimal, error) {
+	if scale < 0 || scale > maxScale {
+		return Decimal{}, fmt.Errorf("invalid decimal scale %d", scale)
+	}
+
+	return Decimal{units: units, scale: uint8(scale)}, nil
+}
+
+// ParseDecimal parses a decimal such as "-1204.10". Exponents are not
+// accepted.
+func ParseDecimal(s string) (Decimal, error) {
+	var d Decimal
+
+	digits := strings.TrimLeft(s, "+-")
+	neg := len(s)-len(digits) == 1 && s[0] == '-'
+
+	if len(s)-len(digits) > 1 {
+		return d, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	whole, frac, _ := strings.Cut(digits, ".")
+
+	if whole == "" && frac == "" || len(frac) > maxScale {
+		return d, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return d, fmt.Errorf("invalid decimal %q", s)
+		}
+
+		if d.units > (math.MaxInt64-int64(c-'0'))/10 {
+			return d, errDecimalRange
+		}
+
+		d.units = d.units*10 + int64(c-'0')
+	}
+
+	if neg {
+		d.units = -d.units
+	}
+
+	d.scale = uint8(len(frac))
+
+	return d, nil
+}
+
+// Units returns the value multiplied by 10^Scale.
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	return int(d.scale)
+}
+
+// Float64 returns the nearest float64 to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Empty reports if d was read from an empty cell.
+func (d Decimal) Empty() bool {
+	return d.empty
+}
+
+// String returns d with Scale digits after the decimal point, or "" when it
+// was read from an empty cell.
+func (d Decimal) String() string {
+	if d.empty {
+		return ""
+	}
+
+	s := strconv.FormatInt(d.units, 10)
+
+	if d.scale == 0 {
+		return s
+	}
+
+	sign := ""
+	if d.units < 0 {
+		sign, s = "-", s[1:]
+	}
+
+	if n := int(d.scale) + 1 - len(s); n > 0 {
+		s = strings.Repeat("0", n) + s
+	}
+
+	point := len(s) - int(d.scale)
+
+	return sign + s[:point] + "." + s[point:]
+}
+
+// MarshalCSV writes the decimal with its scale.
+func (d Decimal) MarshalCSV() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalCSV parses the column's value. An empty value is an Empty zero.
+func (d *Decimal) UnmarshalCSV(val string, row *Row) error {
+	if val == "" {
+		*d = Decimal{empty: true}
+		return nil
+	}
+
+	v, err := ParseDecimal(val)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
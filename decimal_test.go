@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in    string
+		units int64
+		scale int
+		out   string
+	}{
+		{"12.50", 1250, 2, "12.50"},
+		{"-0.05", -5, 2, "-0.05"},
+		{"+7", 7, 0, "7"},
+		{".5", 5, 1, "0.5"},
+		{"16777217.01", 1677721701, 2, "16777217.01"},
+		{"9223372036854775807", 9223372036854775807, 0, "9223372036854775807"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimal(test.in)
+
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+
+		if d.Units() != test.units || d.Scale() != test.scale || d.String() != test.out {
+			t.Errorf("%s: got %d %d %s", test.in, d.Units(), d.Scale(), d)
+		}
+	}
+
+	for _, in := range []string{"", "-", "1.2.3", "1e5", "--1", "9223372036854775808", "0.1234567890123456789"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("no error for %q", in)
+		}
+	}
+}
+
+func TestDecimalColumn(t *testing.T) {
+	type D struct {
+		Price Decimal
+	}
+
+	doc := "Price\n16777217.01\n0.10\n\"\"\n-3\n"
+
+	dd := []D{}
+	if err := Unmarshal([]byte(doc), &dd); err != nil {
+		t.Fatal(err)
+	}
+
+	if dd[0].Price.Units() != 1677721701 {
+		t.Errorf("incorrect value %s", dd[0].Price)
+	}
+
+	out, err := Marshal(dd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !dd[2].Price.Empty() || dd[2].Price.Units() != 0 || dd[3].Price.Empty() {
+		t.Errorf("incorrect empty value %+v", dd[2].Price)
+	}
+
+	// encoding/csv writes a record of one empty field as an empty line
+	if string(out) != "Price\n16777217.01\n0.10\n\n-3\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	d, _ := NewDecimal(-5, 3)
+	if d.String() != "-0.005" || d.Float64() != -0.005 {
+		t.Errorf("incorrect decimal %s", d)
+	}
+}
+
+func TestUnmarshalerPointers(t *testing.T) {
+	type P struct {
+		Price *Decimal
+		Born  *day `layout:"2006-01-02"`
+	}
+
+	pp := []P{}
+	if err := Unmarshal([]byte("Price,Born\n1.5,1815-12-10\n,\n"), &pp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pp) != 2 || pp[0].Price.String() != "1.5" || pp[0].Born.Year() != 1815 {
+		t.Errorf("incorrect values %+v", pp)
+	}
+
+	if pp[1].Price != nil || pp[1].Born != nil {
+		t.Errorf("empty cells are not nil %+v", pp[1])
+	}
+}
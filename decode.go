@@ -5,13 +5,17 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 )
 
 // Row is one row of CSV data, indexed by column name or position.
 type Row struct {
-	Columns *[]string // The name of the columns, in order
-	Data    []string  // the data for the row
+	Columns *[]string      // The name of the columns, in order
+	Data    []string       // the data for the row
+	Line    int            // the 1-based line the record starts on, 0 if unknown
+	Offset  int64          // the byte offset of the input after the previous record
+	index   map[string]int // the position of each column name, shared by every row
 }
 
 // At returns the rows data for the column positon i
@@ -21,6 +25,13 @@ func (r *Row) At(i int) string {
 
 // Named returns the row's data for the first columne named 'n'
 func (r *Row) Named(n string) (string, error) {
+	if r.index != nil {
+		if i, ok := r.index[n]; ok {
+			return r.At(i), nil
+		}
+		return "", fmt.Errorf("no column found for %s", n)
+	}
+
 	for i, cn := range *r.Columns {
 		if cn == n {
 			return r.At(i), nil
@@ -31,11 +42,20 @@ func (r *Row) Named(n string) (string, error) {
 }
 
 type decoder struct {
-	csv          *csv.Reader   // the csv document for input
-	reflect.Type               // the underlying struct to decode
-	out          reflect.Value // the slice output
-	cfields      []cfield      //
-	cols         []string      // colum names
+	csv          *csv.Reader    // the csv document for input
+	reflect.Type                // the underlying struct to decode
+	cfields      []cfield       //
+	cols         []string       // colum names
+	index        map[string]int // the position of the first column with each name
+	rows         bool           // the Type is a RowUnmarshaler
+	reuse        bool           // the record and Row are reused between rows
+	workers      int            // the number of goroutines converting records
+	after        bool           // the Type is an AfterUnmarshaler
+	collect      bool           // row errors are collected rather than returned
+	unprotect    bool           // ="..." cells are read as their text
+	skipped      int64          // the bytes read before the csv.Reader, added to offsets
+	lines        int            // the lines read before the csv.Reader, added to line numbers
+	errs         []error        // the collected row errors
 }
 
 // Unmarshaler is the interface implemented by objects which can unmarshall the CSV row itself.
@@ -47,57 +67,238 @@ type Unmarshaler interface {
 	UnmarshalCSV(string, *Row) error
 }
 
+// RowUnmarshaler is implemented by types which decode a whole row themselves,
+// such as those with methods written by cmd/csvgen. Unmarshal prefers it over
+// reflection.
+type RowUnmarshaler interface {
+	UnmarshalCSVRow(*Row) error
+}
+
 // Unmarshal parses the CSV document and stores the result in the value pointed to by v. Only a slice of a struct is allowed for v.
 //
 // The first line of the CSV is document is used for column names.  These are
 // paired to matching exported fields in v's type. See Marshal on how to use tags
-// to map to different names and additional options.
+// to map to different names and additional options. opts change how the
+// document is read, such as the delimiter.
 //
 // Supported Types
 //
 // string, int, float and bool are supported. Any type which implements Unmarshal is also supported.
-func Unmarshal(doc []byte, v interface{}) error {
+// *big.Int, *big.Float and *big.Rat hold values beyond int64 and float64. A
+// *big.Float's precision and rounding are set with tags.
+//
+//   Amount *big.Float `bits:"256" rounding:"ToZero"`
+//
+// time.Time fields are parsed with the layout tag. A pointer field is nil for
+// an empty cell and otherwise points to the decoded value.
+//
+// Validation
+//
+// Tags can check each value once it is decoded. The first failure is returned
+// as a DecodeError wrapping a *ValidationError.
+//
+//   Qty  int    `validate:"min=0,max=9999"`
+//   Name string `validate:"notempty" len:"1..32"`
+//   Flag string `oneof:"Y|N"`
+//   Code string `regex:"^[A-Z]{3}$"`
+//
+// Record Details
+//
+// Fields tagged with the line, raw or offset option are set from the record
+// rather than a column, and are not written by Marshal. line is the 1-based
+// line the record starts on, raw is a copy of the record as a []string or Row,
+// and offset is the byte offset of the input after the previous record, which
+// is where the record starts unless blank or comment lines come before it.
+//
+//   Line   int      `csv:",line"`
+//   Record []string `csv:",raw"`
+//   Offset int64    `csv:",offset"`
+//
+// Raw Records
+//
+// v may also be a *[][]string or a *[]Row. Each record is appended as is, the
+// header line is consumed and every Row shares the same Columns.
+func Unmarshal(doc []byte, v interface{}, opts ...Option) error {
 	rv, err := checkForSlice(v)
 
 	if err != nil {
 		return err
 	}
 
-	dec, err := newDecoder(doc, rv)
+	dec, err := newDecoder(bytes.NewReader(doc), rv.Type().Elem(), opts)
 
 	if err != nil {
 		return err
 	}
 
-	return dec.unmarshal()
+	if dec.reuse {
+		// every line is at most one record
+		rv.Grow(bytes.Count(doc, []byte{'\n'}))
+	}
+
+	return dec.unmarshal(rv)
 }
 
-func (dec *decoder) unmarshal() error {
+// unmarshal appends every remaining record to the out slice
+func (dec *decoder) unmarshal(out reflect.Value) error {
+	switch {
+	case dec.workers > 1:
+		return dec.unmarshalConcurrent(out)
+	case dec.reuse:
+		return dec.unmarshalInPlace(out)
+	}
+
+	o := reflect.New(dec.Type).Elem()
+	zero := reflect.Zero(dec.Type)
+
 	for {
-		raw, err := dec.csv.Read()
+		o.Set(zero)
+		err := dec.decodeNext(&o)
+
+		if err == io.EOF {
+			return dec.collected()
+		}
+
+		keep, err := dec.keep(err, &dec.errs)
 
 		if err != nil {
-			break
-		} else {
-			row := dec.newRow(raw)
-			o := reflect.New(dec.Type).Elem()
-			err := dec.set(row, &o)
+			return err
+		}
+
+		if keep {
+			appendValue(out, o)
+		}
+	}
+}
+
+// unmarshalInPlace decodes each record directly into the next element of out,
+// reusing a single Row.
+func (dec *decoder) unmarshalInPlace(out reflect.Value) error {
+	row := dec.newRow(nil)
+	zero := reflect.Zero(dec.Type)
+
+	for {
+		err := dec.readRow(row)
+
+		if err == io.EOF {
+			return dec.collected()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		n := out.Len()
+
+		if n == out.Cap() {
+			out.Grow(1)
+		}
+
+		out.SetLen(n + 1)
+		el := out.Index(n)
+		el.Set(zero)
+
+		if keep, err := dec.keep(dec.decode(row, &el), &dec.errs); !keep {
+			out.SetLen(n)
+
 			if err != nil {
 				return err
 			}
-			dec.out.Set(reflect.Append(dec.out, o))
 		}
+	}
+}
+
+// appendValue appends v to the slice out. Unlike reflect.Append the slice
+// header is not reallocated on every call.
+func appendValue(out, v reflect.Value) {
+	n := out.Len()
+
+	if n == out.Cap() {
+		out.Grow(1)
+	}
+
+	out.SetLen(n + 1)
+	out.Index(n).Set(v)
+}
+
+// decodeNext reads the next record and decodes it into el. io.EOF is returned
+// when there are no more records.
+func (dec *decoder) decodeNext(el *reflect.Value) error {
+	row := dec.newRow(nil)
+
+	if err := dec.readRow(row); err != nil {
+		return err
+	}
+
+	return dec.decode(row, el)
+}
+
+// readRow reads the next record into row.
+func (dec *decoder) readRow(row *Row) error {
+	offset := dec.csv.InputOffset()
+	raw, err := dec.csv.Read()
+
+	if err != nil {
+		return shiftLines(err, dec.lines)
+	}
+
+	if dec.unprotect {
+		for i, v := range raw {
+			raw[i] = unprotect(v)
+		}
+	}
+
+	row.Data = raw
+	row.Line, _ = dec.csv.FieldPos(0)
+	row.Line += dec.lines
+	row.Offset = offset + dec.skipped
+
+	return nil
+}
+
+// decode stores the row in el, either as a raw record or field by field, then
+// calls AfterUnmarshalCSV.
+func (dec *decoder) decode(row *Row, el *reflect.Value) error {
+	switch dec.Type {
+	case recordType:
+		el.Set(reflect.ValueOf(dec.record(row)))
+		return nil
+	case rowType:
+		r := *row
+		r.Data = dec.record(row)
+		el.Set(reflect.ValueOf(r))
+		return nil
+	}
+
+	if dec.rows {
+		if err := el.Addr().Interface().(RowUnmarshaler).UnmarshalCSVRow(row); err != nil {
+			return &DecodeError{Line: row.Line, Err: err}
+		}
+	} else if err := dec.set(row, el); err != nil {
+		return err
+	}
 
+	if dec.after {
+		return dec.afterUnmarshal(row, el)
 	}
 
 	return nil
+}
 
+// record returns the row's data, copied when the csv.Reader reuses it.
+func (dec *decoder) record(row *Row) []string {
+	if dec.reuse {
+		return append([]string(nil), row.Data...)
+	}
+
+	return row.Data
 }
 
 func (dec *decoder) newRow(raw []string) *Row {
 	return &Row{
 		Columns: &dec.cols,
 		Data:    raw,
+		index:   dec.index,
 	}
 }
 
@@ -118,6 +319,14 @@ func checkForSlice(v interface{}) (reflect.Value, error) {
 	return rv, nil
 }
 
+var (
+	// element types which receive the raw records instead of a struct
+	recordType = reflect.TypeOf([]string{})
+	rowType    = reflect.TypeOf(Row{})
+
+	unmarshalerType = reflect.TypeOf(new(Unmarshaler)).Elem()
+)
+
 const (
 	// interface is implemented on a value
 	impsVal int = 1
@@ -157,6 +366,13 @@ func (dec *decoder) mapFieldsToCols(cols []string) {
 
 	for _, f := range pFields {
 
+		if meta := metaOption(f.Tag); meta != "" {
+			cf := newCfield(-1, f)
+			cf.assignMeta(meta)
+			dec.cfields = append(dec.cfields, cf)
+			continue
+		}
+
 		name, ok := fieldHeaderName(*f)
 		if ok == false {
 			continue
@@ -164,15 +380,19 @@ func (dec *decoder) mapFieldsToCols(cols []string) {
 
 		index, ok := cMap[name]
 
-		if ok == true {
-			cf := newCfield(index, f)
-
-			if code, err := impsUnmarshaller(f.Type, new(Unmarshaler)); err == nil {
-				cf.assignUnmarshaller(code)
-			} else {
-				cf.assignDecoder()
+		// a ColumnsMarshaler decodes from the first of its columns
+		if cols, multi := fieldColumns(f.Type); multi && !ok {
+			for _, c := range cols {
+				if index, ok = cMap[c]; ok {
+					break
+				}
 			}
+		}
 
+		if ok == true {
+			cf := newCfield(index, f)
+			cf.assign(newField(f, cols[index], index))
+			cf.assignRules()
 			dec.cfields = append(dec.cfields, cf)
 		}
 	}
@@ -200,38 +420,92 @@ func exportedFields(t reflect.Type) []*reflect.StructField {
 
 }
 
-func newDecoder(doc []byte, rv reflect.Value) (*decoder, error) {
-	b := bytes.NewReader(doc)
-	r := csv.NewReader(b)
-	cols, err := r.Read()
+// newDecoder reads the header line from r and maps the columns to el, which
+// must be a struct, []string or Row.
+func newDecoder(r io.Reader, el reflect.Type, opts []Option) (*decoder, error) {
+	if el.Kind() != reflect.Struct && el != recordType {
+		return nil, fmt.Errorf("only structs, []string or Row can be decoded: %s", el)
+	}
+
+	o := newOptions(opts)
+	r = o.decodeCharset(r)
 
-	if err != nil {
-		return nil, err
+	var skipped int64
+	var lines int
+
+	if o.detect || o.dialect != nil {
+		r, skipped, lines = o.readDialect(r)
 	}
 
-	el := rv.Type().Elem()
+	cr := csv.NewReader(r)
+	o.applyReader(cr)
+
+	// workers keep records after the next is read
+	if o.workers > 1 {
+		cr.ReuseRecord = false
+	}
+
+	var cols []string
+	var err error
+
+	switch {
+	case o.header():
+		cols, err = cr.Read()
+	case el.Kind() == reflect.Struct && el != rowType:
+		cols = encodePlanFor(el).cols
+
+		// a record with fewer fields is a csv.ParseError
+		if len(cols) > 0 {
+			cr.FieldsPerRecord = len(cols)
+		}
+	}
+
+	if err != nil {
+		return nil, shiftLines(err, lines)
+	}
 
 	dec := decoder{
-		Type: el,
-		csv:  r,
-		out:  rv,
-		cols: cols,
+		Type:      el,
+		csv:       cr,
+		cols:      cols,
+		index:     columnIndex(cols),
+		reuse:     o.reuse && o.workers < 2,
+		workers:   o.workers,
+		after:     reflect.PointerTo(el).Implements(afterUnmarshalerType),
+		unprotect: o.protect(),
+		collect:   o.collect,
+		skipped:   skipped,
+		lines:     lines,
 	}
 
-	dec.mapFieldsToCols(cols)
+	switch {
+	case el == recordType || el == rowType:
+	case reflect.PointerTo(el).Implements(rowUnmarshalerType):
+		dec.rows = true
+	default:
+		dec.cfields = dec.cfieldsFor(cols)
+	}
 
 	return &dec, nil
 }
 
 // Sets each field value for the el struct for the given row
 func (dec *decoder) set(row *Row, el *reflect.Value) error {
-	for _, cf := range dec.cfields {
-		field := cf.structField
-		f := el.FieldByName(field.Name)
-		err := cf.decoder(&f, row)
+	for i := range dec.cfields {
+		cf := &dec.cfields[i]
+		cell := el.FieldByIndex(cf.index)
+		err := cf.decoder(cell, row)
+
+		if err == nil && cf.rules != nil {
+			err = cf.validate(cell, row)
+		}
 
 		if err != nil {
-			return err
+			de := &DecodeError{Line: row.Line, Err: err}
+			if cf.colIndex >= 0 {
+				de.Column = dec.cols[cf.colIndex]
+			}
+			return de
 		}
 	}
 
@@ -1,6 +1,8 @@
 package csv
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -18,8 +20,8 @@ type Q struct {
 func TestUnmarshal(t *testing.T) {
 	doc := []byte(`String,Int,unexported,Bool,Float32,Float64,C64
 John,23,1,Yes,32.2,64.1,1
-Jane,27,2,No,33.1,65.1,2
-Bill,28,3,Yes,34.7,65.1,3`)
+Jane,27,2,No,33.1,65.1,(+2-1.5i)
+Bill,28,3,Yes,34.7,65.1,3i`)
 
 	pp := []Q{}
 
@@ -40,6 +42,7 @@ Bill,28,3,Yes,34.7,65.1,3`)
 	bools := []bool{true, false, true}
 	f32s := []float32{32.2, 33.1, 34.7}
 	f64s := []float64{64.1, 65.1, 65.1}
+	c64s := []complex64{1, 2 - 1.5i, 3i}
 
 	for i, p := range pp {
 		assert(strs[i], p.String)
@@ -47,6 +50,7 @@ Bill,28,3,Yes,34.7,65.1,3`)
 		assert(bools[i], p.Bool)
 		assert(f32s[i], p.Float32)
 		assert(f64s[i], p.Float64)
+		assert(c64s[i], p.Complex64)
 	}
 
 }
@@ -143,3 +147,351 @@ Jay,23`
 		t.Errorf("custom unmarshal did not work (%s)", oo[0].Name.V)
 	}
 }
+
+func TestUnmarshalRaw(t *testing.T) {
+	doc := []byte(`Name,Age
+Jay,23
+Kay,31`)
+
+	recs := [][]string{}
+	if err := Unmarshal(doc, &recs); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 2 || recs[1][0] != "Kay" || recs[1][1] != "31" {
+		t.Errorf("incorrect records %v", recs)
+	}
+
+	rows := []Row{}
+	if err := Unmarshal(doc, &rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("incorrect row length %d", len(rows))
+	}
+
+	if rows[0].Columns != rows[1].Columns {
+		t.Error("rows do not share columns")
+	}
+
+	if v, _ := rows[1].Named("Age"); v != "31" {
+		t.Errorf("expected 31 got %s", v)
+	}
+}
+
+func TestUnmarshalReuseRecord(t *testing.T) {
+	doc := []byte(`Name,Age
+Jay,23
+Kay,31`)
+
+	gg := []gen{{"Old", 1}}
+	if err := Unmarshal(doc, &gg, ReuseRecord()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gg) != 3 || gg[1] != (gen{"Jay", 23}) || gg[2] != (gen{"Kay", 31}) {
+		t.Errorf("incorrect results %v", gg)
+	}
+
+	recs := [][]string{}
+	if err := Unmarshal(doc, &recs, ReuseRecord()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 2 || recs[0][0] != "Jay" || recs[1][0] != "Kay" {
+		t.Errorf("records were not copied %v", recs)
+	}
+
+	rows := []Row{}
+	if err := Unmarshal(doc, &rows, ReuseRecord()); err != nil {
+		t.Fatal(err)
+	}
+
+	if rows[0].At(0) != "Jay" || rows[1].At(0) != "Kay" {
+		t.Errorf("rows were not copied %v", rows)
+	}
+
+	gg = []gen{}
+	err := Unmarshal([]byte("Name,Age\nJay,23\nKay,old"), &gg, ReuseRecord())
+	if err == nil || len(gg) != 1 {
+		t.Errorf("expected an error and 1 row got %v %v", err, gg)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	doc := []byte(`String,Int
+John,23
+Jane,old`)
+
+	pp := []Q{}
+	err := Unmarshal(doc, &pp)
+
+	de, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("expected a DecodeError got %v", err)
+	}
+
+	if de.Line != 3 || de.Column != "Int" {
+		t.Errorf("incorrect error position %v", de)
+	}
+
+	if len(pp) != 1 {
+		t.Errorf("expected 1 row before the error got %d", len(pp))
+	}
+}
+
+func TestUnmarshalNumberFormat(t *testing.T) {
+	type N struct {
+		Income int     `locale:"en"`
+		Amount float64 `decimal:"," group:"."`
+		Rate   float32 `locale:"fr"`
+		Plain  float64
+	}
+
+	doc := []byte(`Income,Amount,Rate,Plain
+"32,000","1.234,56","1 000,5",1.5`)
+
+	nn := []N{}
+	if err := Unmarshal(doc, &nn); err != nil {
+		t.Fatal(err)
+	}
+
+	if nn[0] != (N{32000, 1234.56, 1000.5, 1.5}) {
+		t.Errorf("incorrect values %+v", nn[0])
+	}
+
+	type Bad struct {
+		Income int `locale:"xx"`
+	}
+
+	if err := Unmarshal(doc, &[]Bad{}); err == nil {
+		t.Error("no error for an unknown locale")
+	}
+
+	if _, err := Marshal([]Bad{{1}}); err == nil {
+		t.Error("no error marshalling an unknown locale")
+	}
+}
+
+func TestUnmarshalCurrency(t *testing.T) {
+	type C struct {
+		Price float64 `currency:"$" locale:"en" csv:",accounting"`
+		Cost  float32 `currency:"€" locale:"de"`
+		Rate  float64 `csv:",percent"`
+		Count int     `currency:"$"`
+	}
+
+	doc := []byte(`Price,Cost,Rate,Count
+"$1,204.10","1.204,50 €",12.5%,$7
+($45.00),-3 €,-0.5 %,-$2`)
+
+	cc := []C{}
+	if err := Unmarshal(doc, &cc); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []C{{1204.10, 1204.5, 0.125, 7}, {-45, -3, -0.005, -2}}
+
+	for i, c := range cc {
+		if c != expected[i] {
+			t.Errorf("expected %+v got %+v", expected[i], c)
+		}
+	}
+
+	type Bad struct {
+		Count int `csv:",percent"`
+	}
+
+	if err := Unmarshal(doc, &[]Bad{}); err == nil {
+		t.Error("no error for a percent int")
+	}
+
+	if _, err := Marshal([]Bad{{1}}); err == nil {
+		t.Error("no error marshalling a percent int")
+	}
+
+	// the documented accounting format reads back what it writes
+	type A struct {
+		Price float64 `currency:"$" locale:"en" format:"%.2f" csv:",accounting"`
+	}
+
+	out, err := Marshal([]A{{-1204.1}})
+	aa := []A{}
+	if err != nil || string(out) != "Price\n\"($1,204.10)\"\n" || Unmarshal(out, &aa) != nil || aa[0].Price != -1204.1 {
+		t.Errorf("incorrect round trip %q %v %+v", out, err, aa)
+	}
+}
+
+func TestUnmarshalComplex(t *testing.T) {
+	type C struct {
+		A complex128
+		B complex64
+	}
+
+	in := []C{{1.0000001 - 2e-9i, 0.1 + 0.2i}, {complex(3, 0), 4i}}
+
+	out, err := Marshal(in, FloatFormat('g', -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cc := []C{}
+	if err := Unmarshal(out, &cc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(in, cc) {
+		t.Errorf("complex values did not round trip\n%s%v", out, cc)
+	}
+
+	err = Unmarshal([]byte("A\n1+i2\n"), &cc)
+	if de, ok := err.(*DecodeError); !ok || de.Column != "A" {
+		t.Errorf("expected a DecodeError got %v", err)
+	}
+}
+
+func TestUnmarshalValidation(t *testing.T) {
+	type V struct {
+		Qty  int    `validate:"min=0,max=9999"`
+		Name string `validate:"notempty" len:"1..8"`
+		Flag string `oneof:"Y|N"`
+		Code string `regex:"^[A-Z]{3}$"`
+	}
+
+	tests := []struct {
+		doc, column, rule string
+	}{
+		{"1,Jo,Y,ABC", "", ""},
+		{"-1,Jo,Y,ABC", "Qty", "min=0"},
+		{"10000,Jo,Y,ABC", "Qty", "max=9999"},
+		{"1,,Y,ABC", "Name", "notempty"},
+		{"1,Jonathan Smith,Y,ABC", "Name", "len=1..8"},
+		{"1,Jo,y,ABC", "Flag", "oneof=Y|N"},
+		{"1,Jo,N,AB1", "Code", "regex=^[A-Z]{3}$"},
+	}
+
+	for _, test := range tests {
+		vv := []V{}
+		err := Unmarshal([]byte("Qty,Name,Flag,Code\n"+test.doc), &vv)
+
+		if test.rule == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.doc, err)
+			}
+			continue
+		}
+
+		var de *DecodeError
+		var ve *ValidationError
+
+		if !errors.As(err, &de) || !errors.As(err, &ve) {
+			t.Errorf("%s: expected a ValidationError got %v", test.doc, err)
+			continue
+		}
+
+		if de.Line != 2 || de.Column != test.column || ve.Rule != test.rule {
+			t.Errorf("%s: incorrect error %v", test.doc, err)
+		}
+	}
+
+	type Bad struct {
+		Name string `validate:"min=1"`
+	}
+
+	if err := Unmarshal([]byte("Name\nx"), &[]Bad{}); err == nil {
+		t.Error("no error for min on a string field")
+	}
+
+	type P struct {
+		Qty *int `validate:"min=0"`
+	}
+
+	pp := []P{}
+	if err := Unmarshal([]byte("Qty\n3\n\"\"\n"), &pp); err != nil || len(pp) != 2 || *pp[0].Qty != 3 || pp[1].Qty != nil {
+		t.Errorf("incorrect pointer rows %+v %v", pp, err)
+	}
+
+	if err := Unmarshal([]byte("Qty\n-3\n"), &pp); !errors.As(err, new(*ValidationError)) {
+		t.Errorf("expected a ValidationError for a pointer got %v", err)
+	}
+}
+
+func TestUnmarshalRecordDetails(t *testing.T) {
+	type D struct {
+		Name   string
+		Line   int      `csv:",line"`
+		Offset int64    `csv:",offset"`
+		Record []string `csv:",raw"`
+		Row    Row      `csv:",raw"`
+	}
+
+	doc := []byte("Name,Age\nAda,36\n\"Bob\nJr\",40\nCy,5\n")
+
+	for _, opts := range [][]Option{nil, {ReuseRecord()}, {Concurrency(2)}} {
+		dd := []D{}
+		if err := Unmarshal(doc, &dd, opts...); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := []int{dd[0].Line, dd[1].Line, dd[2].Line}
+		offsets := []int64{dd[0].Offset, dd[1].Offset, dd[2].Offset}
+
+		if !reflect.DeepEqual(lines, []int{2, 3, 5}) || !reflect.DeepEqual(offsets, []int64{9, 16, 28}) {
+			t.Errorf("incorrect lines %v or offsets %v", lines, offsets)
+		}
+
+		if !reflect.DeepEqual(dd[1].Record, []string{"Bob\nJr", "40"}) || dd[2].Row.At(1) != "5" || dd[2].Row.Line != 5 {
+			t.Errorf("incorrect records %v", dd)
+		}
+
+		out, err := Marshal(dd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(out) != "Name\nAda\n\"Bob\nJr\"\nCy\n" {
+			t.Errorf("record details were marshalled %q", out)
+		}
+	}
+
+	type Bad struct {
+		Line string `csv:",line"`
+	}
+
+	if err := Unmarshal(doc, &[]Bad{}); err == nil {
+		t.Error("no error for a string line field")
+	}
+}
+
+func TestDecodePlans(t *testing.T) {
+	type K struct {
+		A string
+		B string
+	}
+
+	// a header containing the separator of a joined key
+	if err := Unmarshal([]byte("A\x00B\nx\n"), &[]K{}); err != nil {
+		t.Fatal(err)
+	}
+
+	kk := []K{}
+	if err := Unmarshal([]byte("A,B\n1,2\n"), &kk); err != nil || len(kk) != 1 || kk[0] != (K{"1", "2"}) {
+		t.Errorf("incorrect rows %+v %v", kk, err)
+	}
+
+	if headerKey([]string{"A\x00B"}) == headerKey([]string{"A", "B"}) {
+		t.Error("two headers have the same key")
+	}
+
+	// a full cache is emptied rather than no longer used
+	for i := 0; i <= planLimit; i++ {
+		Unmarshal([]byte(fmt.Sprintf("A,B,C%d\n", i)), &[]K{})
+	}
+
+	Unmarshal([]byte("B,A\n"), &[]K{})
+
+	if _, ok := decodePlans.Load(planKey{reflect.TypeFor[K](), headerKey([]string{"B", "A"})}); !ok {
+		t.Error("the plan was not cached once the cache was full")
+	}
+}
@@ -0,0 +1,383 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// sniffSize is the most input Sniff and AutoDetect inspect
+const sniffSize = 64 << 10
+
+var bom = []byte("\xEF\xBB\xBF")
+
+// Dialect describes the format of a CSV document. It is detected by Sniff and
+// applied with WithDialect.
+type Dialect struct {
+	Comma   rune // the field delimiter
+	Comment rune // the character starting a comment line, 0 when there are none
+	Quoted  bool // some fields are quoted; encoding/csv quotes only when needed
+	CRLF    bool // lines end with \r\n
+	BOM     bool // the document starts with a UTF-8 byte order mark
+	Header  bool // the first record is a header
+	SepLine bool // a first line such as sep=; names the delimiter, as Excel writes
+	Protect bool // values such as ="00123" are protected from conversion by Excel
+}
+
+// Excel is the Dialect of files for Excel. The byte order mark makes Excel
+// read UTF-8, and numbers Excel would change, those with leading zeros or more
+// than 15 digits, are written as ="00123" so they stay text. Where Excel uses
+// ';' set Comma and SepLine, though Excel then ignores the byte order mark.
+//
+//	d := csv.Excel
+//	d.Comma, d.SepLine = ';', true
+var Excel = Dialect{Comma: ',', CRLF: true, BOM: true, Header: true, Protect: true}
+
+// WithDialect reads and writes documents in the Dialect d, replacing the
+// Comma and Comment options. When reading, a byte order mark is skipped and a
+// sep= line sets the delimiter whatever d says. When d has no Header the first
+// record is decoded as a record with the columns Marshal would write, which
+// every record must have, and Marshal writes no header.
+func WithDialect(d Dialect) Option {
+	return func(o *options) {
+		o.useDialect(d)
+	}
+}
+
+func (o *options) useDialect(d Dialect) {
+	o.comma = d.Comma
+	o.comment = d.Comment
+	o.dialect = &d
+}
+
+// Sniff reads a sample of up to 64KB from r and detects its Dialect. The
+// delimiter is the one of ',', '\t', ';' and '|' which splits the most records
+// into the same number of fields. Lines starting with '#' are comments when
+// they come before the header, so a value such as #N/A in a later record is
+// not taken for one. The first record is a header unless its values look like
+// the values of the records after it, as when they are all numbers.
+func Sniff(r io.Reader) (Dialect, error) {
+	sample, err := io.ReadAll(io.LimitReader(r, sniffSize))
+
+	if err != nil {
+		return Dialect{}, err
+	}
+
+	if len(sample) == 0 {
+		return Dialect{}, errors.New("csv: nothing to sniff")
+	}
+
+	return sniff(sample, len(sample) < sniffSize), nil
+}
+
+// AutoDetect detects the Dialect of the input before the header is read and
+// reads it as WithDialect does.
+func AutoDetect() Option {
+	return func(o *options) {
+		o.detect = true
+	}
+}
+
+// readDialect detects the Dialect when AutoDetect is set, then skips a byte
+// order mark and a sep= line. The returned reader replaces r, and skipped and
+// lines are the bytes and lines it does not read, which line numbers and
+// offsets still count.
+func (o *options) readDialect(r io.Reader) (_ io.Reader, skipped int64, lines int) {
+	br := bufio.NewReaderSize(r, sniffSize)
+
+	if o.detect {
+		sample, err := br.Peek(sniffSize)
+		o.useDialect(sniff(sample, err != nil))
+	}
+
+	if b, _ := br.Peek(len(bom)); bytes.Equal(b, bom) {
+		br.Discard(len(bom))
+		skipped += int64(len(bom))
+	}
+
+	// a sep= line is short, but utf8.UTFMax bytes long at least
+	b, _ := br.Peek(16)
+
+	if c, n := sepLine(b); n > 0 {
+		br.Discard(n)
+		o.comma = c
+		skipped += int64(n)
+		lines++
+	}
+
+	return br, skipped, lines
+}
+
+// shiftLines adds the lines readDialect skipped to the lines of a
+// csv.ParseError.
+func shiftLines(err error, lines int) error {
+	pe, ok := err.(*csv.ParseError)
+
+	if !ok || lines == 0 {
+		return err
+	}
+
+	shifted := *pe
+	shifted.StartLine += lines
+	shifted.Line += lines
+
+	return &shifted
+}
+
+// writePreamble writes the byte order mark and sep= line of the Dialect. A
+// UTF16 document always starts with a byte order mark.
+func writePreamble(w io.Writer, o *options) error {
+	d := o.dialect
+
+	if d == nil {
+		d = &Dialect{}
+	}
+
+	var b []byte
+
+	if d.BOM || o.charset == UTF16 {
+		b = append(b, bom...)
+	}
+
+	if d.SepLine {
+		b = append(b, "sep="...)
+		b = utf8.AppendRune(b, o.comma)
+
+		if d.CRLF {
+			b = append(b, '\r')
+		}
+		b = append(b, '\n')
+	}
+
+	if len(b) == 0 {
+		return nil
+	}
+
+	_, err := w.Write(b)
+	return err
+}
+
+// sepLine returns the delimiter of a sep= line at the start of b and the
+// length of the line, which is 0 when there is none.
+func sepLine(b []byte) (rune, int) {
+	rest, ok := bytes.CutPrefix(b, []byte("sep="))
+	if !ok {
+		return 0, 0
+	}
+
+	c, size := utf8.DecodeRune(rest)
+	rest = rest[size:]
+
+	switch {
+	case c == utf8.RuneError:
+		return 0, 0
+	case bytes.HasPrefix(rest, []byte("\r\n")):
+		return c, 4 + size + 2
+	case bytes.HasPrefix(rest, []byte("\n")):
+		return c, 4 + size + 1
+	}
+
+	return 0, 0
+}
+
+// protect writes a cell Excel would read as a number, losing leading zeros
+// or digits after the 15th, as a formula for the text.
+func protect(cell string) string {
+	if cell == "" || strings.Trim(cell, "0123456789") != "" {
+		return cell
+	}
+
+	if len(cell) > 15 || len(cell) > 1 && cell[0] == '0' {
+		return `="` + cell + `"`
+	}
+
+	return cell
+}
+
+// unprotect returns the text of a ="..." cell.
+func unprotect(cell string) string {
+	if len(cell) > 2 && strings.HasPrefix(cell, `="`) && strings.HasSuffix(cell, `"`) {
+		return cell[2 : len(cell)-1]
+	}
+
+	return cell
+}
+
+// sniff detects the Dialect of sample. Unless atEOF the sample's last line may
+// be cut short and is left out.
+func sniff(sample []byte, atEOF bool) Dialect {
+	d := Dialect{Comma: ',', Header: true}
+
+	if bytes.HasPrefix(sample, bom) {
+		d.BOM = true
+		sample = sample[len(bom):]
+	}
+
+	if i := bytes.LastIndexByte(sample, '\n'); !atEOF && i >= 0 {
+		sample = sample[:i+1]
+	}
+
+	d.CRLF = bytes.Contains(sample, []byte("\r\n"))
+
+	sep, n := sepLine(sample)
+	if n > 0 {
+		d.Comma, d.SepLine = sep, true
+		sample = sample[n:]
+	}
+
+	comments := 0
+	for _, l := range bytes.Split(sample, []byte("\n")) {
+		if len(bytes.TrimSpace(l)) == 0 {
+			continue
+		}
+
+		if l[0] != '#' {
+			if comments > 0 {
+				d.Comment = '#'
+			}
+			break
+		}
+
+		comments++
+	}
+
+	var best [][]string
+	bestScore, bestFields := 0.0, 1
+
+	if d.SepLine {
+		best = sampleRecords(sample, d.Comma, d.Comment)
+	}
+
+	for _, c := range []rune{',', '\t', ';', '|'} {
+		if d.SepLine {
+			break
+		}
+
+		recs := sampleRecords(sample, c, d.Comment)
+		fields, score := consistency(recs)
+
+		if fields > 1 && (score > bestScore || score == bestScore && fields > bestFields) {
+			d.Comma, best, bestScore, bestFields = c, recs, score, fields
+		}
+	}
+
+	recs := best
+	if recs == nil {
+		recs = sampleRecords(sample, d.Comma, d.Comment)
+	}
+
+	for _, r := range recs {
+		for _, v := range r {
+			if unprotect(v) != v {
+				d.Protect = true
+			}
+		}
+	}
+
+	for i, b := range sample {
+		if b == '"' && (i == 0 || sample[i-1] == '\n' || rune(sample[i-1]) == d.Comma) {
+			d.Quoted = true
+			break
+		}
+	}
+
+	d.Header = hasHeader(best)
+
+	return d
+}
+
+// sampleRecords reads the records of sample, stopping at the first error.
+func sampleRecords(sample []byte, comma, comment rune) [][]string {
+	cr := csv.NewReader(bytes.NewReader(sample))
+	cr.Comma = comma
+	cr.Comment = comment
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	var recs [][]string
+
+	for {
+		rec, err := cr.Read()
+		if err != nil {
+			return recs
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// consistency returns the most common number of fields in recs and the share
+// of the records which have it.
+func consistency(recs [][]string) (int, float64) {
+	counts := map[int]int{}
+	fields, most := 0, 0
+
+	for _, r := range recs {
+		counts[len(r)]++
+
+		if n := counts[len(r)]; n > most || n == most && len(r) > fields {
+			fields, most = len(r), n
+		}
+	}
+
+	if len(recs) == 0 {
+		return 0, 0
+	}
+
+	return fields, float64(most) / float64(len(recs))
+}
+
+// hasHeader votes on each column. A column whose values are all numbers, or
+// all the same length, votes for a header when the first value is not like
+// them and against one when it is.
+func hasHeader(recs [][]string) bool {
+	if len(recs) < 2 {
+		return true
+	}
+
+	votes := 0
+
+	for i, h := range recs[0] {
+		numeric, length, sameLen, seen := true, -1, true, false
+
+		for _, r := range recs[1:] {
+			if i >= len(r) || r[i] == "" {
+				continue
+			}
+
+			seen = true
+
+			if _, err := strconv.ParseFloat(r[i], 64); err != nil {
+				numeric = false
+			}
+
+			if length == -1 {
+				length = len(r[i])
+			} else if len(r[i]) != length {
+				sameLen = false
+			}
+		}
+
+		switch {
+		case !seen:
+		case numeric:
+			if _, err := strconv.ParseFloat(h, 64); err != nil {
+				votes++
+			} else {
+				votes--
+			}
+		case sameLen:
+			if len(h) != length {
+				votes++
+			} else {
+				votes--
+			}
+		}
+	}
+
+	return votes >= 0
+}
@@ -0,0 +1,169 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		d    Dialect
+	}{
+		{"comma", "Name,Age\nAda,36\nBob,40\n", Dialect{Comma: ',', Header: true}},
+		{"semicolon", "\xEF\xBB\xBFName;Price\r\n\"Ada, L\";1,5\r\nBob;2,25\r\n",
+			Dialect{Comma: ';', Quoted: true, CRLF: true, BOM: true, Header: true}},
+		{"tab", "a\tb\tc\n1\t2\t3\n4\t5\t6\n", Dialect{Comma: '\t', Header: true}},
+		{"pipe", "id|name\n1|x,y\n2|z\n", Dialect{Comma: '|', Header: true}},
+		{"comment", "# exported\nA,B\n1,2\n", Dialect{Comma: ',', Comment: '#', Header: true}},
+		{"hash value", "A,B\n#N/A,2\n3,4\n", Dialect{Comma: ',', Header: true}},
+		{"no header", "1,2.5,x\n3,4.5,y\n5,6,z\n", Dialect{Comma: ','}},
+		{"codes", "AB12,x\nCD34,y\nEF56,z\n", Dialect{Comma: ','}},
+		{"excel", "sep=;\r\nZip;Name\r\n\"=\"\"01234\"\"\";Ada\r\n",
+			Dialect{Comma: ';', Quoted: true, CRLF: true, Header: true, SepLine: true, Protect: true}},
+	}
+
+	for _, test := range tests {
+		d, err := Sniff(strings.NewReader(test.doc))
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+
+		if d != test.d {
+			t.Errorf("%s: expected %+v got %+v", test.name, test.d, d)
+		}
+	}
+
+	if _, err := Sniff(strings.NewReader("")); err == nil {
+		t.Error("no error for an empty document")
+	}
+
+	// a line cut short by the sample size is left out
+	long := "A;B\n" + strings.Repeat("1;2\n", sniffSize/4) + "3,4,5,6,7"
+	if d, _ := Sniff(strings.NewReader(long)); d.Comma != ';' {
+		t.Errorf("incorrect delimiter %q for a long document", d.Comma)
+	}
+}
+
+func TestAutoDetect(t *testing.T) {
+	type P struct {
+		Name  string
+		Price float64 `decimal:","`
+	}
+
+	doc := []byte("\xEF\xBB\xBF# skipped\r\nName;Price\r\n\"Ada; L\";1,5\r\n# skipped\r\nBob;2,25\r\n")
+
+	pp := []P{}
+	if err := Unmarshal(doc, &pp, AutoDetect()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pp) != 2 || pp[0] != (P{"Ada; L", 1.5}) || pp[1] != (P{"Bob", 2.25}) {
+		t.Errorf("incorrect rows %+v", pp)
+	}
+
+	// without a header the columns are those Marshal writes
+	type N struct {
+		A int
+		B float64
+	}
+
+	nn, err := ReadAll[N](bytes.NewReader([]byte("1|2.5\n3|4.5\n")), AutoDetect())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nn) != 2 || nn[0] != (N{1, 2.5}) {
+		t.Errorf("incorrect rows %+v", nn)
+	}
+
+	// a headerless record must have every column
+	var pe *csv.ParseError
+	err = Unmarshal([]byte("1\n2\n"), &[]struct{ A, B string }{}, WithDialect(Dialect{Comma: ','}))
+	if !errors.As(err, &pe) || pe.Line != 1 {
+		t.Errorf("expected a ParseError for a short record got %v", err)
+	}
+
+	// offsets count the byte order mark
+	rr, err := ReadAll[Row](bytes.NewReader([]byte("\xEF\xBB\xBFA,B\n#N/A,1\n")), AutoDetect())
+	if err != nil || len(rr) != 1 || rr[0].At(0) != "#N/A" || rr[0].Offset != 7 || rr[0].Line != 2 {
+		t.Errorf("incorrect rows %+v %v", rr, err)
+	}
+}
+
+func TestExcel(t *testing.T) {
+	type A struct {
+		Zip  string
+		ID   string
+		Name string
+	}
+
+	aa := []A{{"01234", "1234567890123456", "Ada"}, {"0", "42", "Bob"}}
+
+	out, err := Marshal(aa, WithDialect(Excel))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\xEF\xBB\xBFZip,ID,Name\r\n" +
+		`"=""01234""","=""1234567890123456""",Ada` + "\r\n" +
+		"0,42,Bob\r\n"
+
+	if string(out) != expected {
+		t.Errorf("expected %q got %q", expected, out)
+	}
+
+	for _, opts := range [][]Option{{WithDialect(Excel)}, {AutoDetect()}} {
+		back := []A{}
+		if err := Unmarshal(out, &back, opts...); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(back) != 2 || back[0] != aa[0] || back[1] != aa[1] {
+			t.Errorf("incorrect rows %+v", back)
+		}
+	}
+
+	// a sep= line is written, and read whatever the Dialect's delimiter
+	d := Excel
+	d.Comma, d.SepLine, d.BOM, d.Header = ';', true, false, false
+
+	out, err = Marshal(aa[1:], WithDialect(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "sep=;\r\n0;42;Bob\r\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	back := []A{}
+	err = Unmarshal([]byte("sep=|\nZip|ID|Name\n=\"007\"|1|Ada\n"), &back, WithDialect(Excel))
+	if err != nil || len(back) != 1 || back[0] != (A{"007", "1", "Ada"}) {
+		t.Errorf("incorrect rows %+v %v", back, err)
+	}
+
+	// lines and offsets count the byte order mark and sep= line
+	type L struct {
+		A      string
+		Line   int   `csv:",line"`
+		Offset int64 `csv:",offset"`
+	}
+
+	ll := []L{}
+	err = Unmarshal([]byte("\xEF\xBB\xBFsep=,\r\nA\r\nxx\r\n"), &ll, WithDialect(Excel))
+	if err != nil || len(ll) != 1 || ll[0] != (L{"xx", 3, 13}) {
+		t.Errorf("incorrect rows %+v %v", ll, err)
+	}
+
+	var pe *csv.ParseError
+	err = Unmarshal([]byte("sep=,\nA\nx\"y\n"), &ll, WithDialect(Dialect{Comma: ',', Header: true}))
+	if !errors.As(err, &pe) || pe.Line != 3 {
+		t.Errorf("expected a ParseError on line 3 got %v", err)
+	}
+}
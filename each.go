@@ -0,0 +1,114 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var (
+	// Stop is returned by a row function to end decoding without an error.
+	Stop = errors.New("csv: stop")
+
+	// SkipRow is returned by a row function to ignore the row and continue.
+	SkipRow = errors.New("csv: skip row")
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Each decodes every record in r and calls fn with it. Decoding stops at the
+// first error returned by fn, which is returned unless it is Stop. Rows which
+// are skipped, or fail with CollectErrors, are not given to fn.
+//
+// The same T, record and Row are reused for every row, so fn must copy
+// anything it keeps.
+func Each[T any](r io.Reader, fn func(*T) error, opts ...Option) error {
+	dec, err := newDecoder(r, reflect.TypeFor[T](), opts)
+
+	if err != nil {
+		return err
+	}
+
+	var v T
+
+	return dec.each(reflect.ValueOf(&v).Elem(), func() error {
+		return fn(&v)
+	})
+}
+
+// UnmarshalFunc is Each for callers without a type parameter. fn must be a
+// func(*T) error where T follows the same rules as Unmarshal.
+func UnmarshalFunc(r io.Reader, fn interface{}, opts ...Option) error {
+	fv := reflect.ValueOf(fn)
+
+	if !fv.IsValid() {
+		return fmt.Errorf("fn must be a func(*T) error: %v", fn)
+	}
+
+	ft := fv.Type()
+
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 ||
+		ft.In(0).Kind() != reflect.Ptr || ft.Out(0) != errorType {
+		return fmt.Errorf("fn must be a func(*T) error: %s", ft)
+	}
+
+	if fv.IsNil() {
+		return fmt.Errorf("fn must be a func(*T) error: nil %s", ft)
+	}
+
+	dec, err := newDecoder(r, ft.In(0).Elem(), opts)
+
+	if err != nil {
+		return err
+	}
+
+	pv := reflect.New(dec.Type)
+	args := []reflect.Value{pv}
+
+	return dec.each(pv.Elem(), func() error {
+		err, _ := fv.Call(args)[0].Interface().(error)
+		return err
+	})
+}
+
+// each decodes every remaining record into el and calls fn after each one.
+// The record and Row are reused since el is overwritten on every row.
+func (dec *decoder) each(el reflect.Value, fn func() error) error {
+	zero := reflect.Zero(dec.Type)
+	row := dec.newRow(nil)
+	dec.csv.ReuseRecord = true
+
+	for {
+		err := dec.readRow(row)
+
+		if err == io.EOF {
+			return dec.collected()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		el.Set(zero)
+
+		keep, err := dec.keep(dec.decode(row, &el), &dec.errs)
+
+		if err != nil {
+			return err
+		}
+
+		if !keep {
+			continue
+		}
+
+		switch err := fn(); err {
+		case nil, SkipRow:
+			continue
+		case Stop:
+			return dec.collected()
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	doc := genDoc + "Bill,40\n"
+	names := []string{}
+
+	err := Each(strings.NewReader(doc), func(g *gen) error {
+		if g.Name == "Kay" {
+			return SkipRow
+		}
+		if g.Age > 35 {
+			return Stop
+		}
+		names = append(names, g.Name)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(names, " ") != "Jay" {
+		t.Errorf("incorrect names %v", names)
+	}
+
+	boom := errors.New("boom")
+	err = Each(strings.NewReader(doc), func(g *gen) error {
+		return boom
+	})
+
+	if err != boom {
+		t.Errorf("expected boom got %v", err)
+	}
+}
+
+func TestUnmarshalFunc(t *testing.T) {
+	total := 0
+
+	err := UnmarshalFunc(strings.NewReader(genDoc), func(g *gen) error {
+		total += g.Age
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 54 {
+		t.Errorf("expected 54 got %d", total)
+	}
+
+	err = UnmarshalFunc(strings.NewReader(genDoc), func(g gen) {})
+
+	if err == nil {
+		t.Error("no error for an invalid func")
+	}
+
+	if err := UnmarshalFunc(strings.NewReader(genDoc), nil); err == nil {
+		t.Error("no error for a nil func")
+	}
+
+	var fn func(*gen) error
+	if err := UnmarshalFunc(strings.NewReader(genDoc), fn); err == nil {
+		t.Error("no error for a typed nil func")
+	}
+}
+
+func BenchmarkEach(b *testing.B) {
+	b.StopTimer()
+	data := loadData()
+	b.StartTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		err := Each(bytes.NewReader(data), func(p *Price) error {
+			return nil
+		})
+
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
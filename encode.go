@@ -5,8 +5,10 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 // Marshaler is an interface for objects which can Marshal themselves into CSV.
@@ -14,9 +16,38 @@ type Marshaler interface {
 	MarshalCSV() ([]byte, error)
 }
 
+// RowMarshaler is implemented by types which encode a whole row themselves,
+// such as those with methods written by cmd/csvgen. Marshal prefers it over
+// reflection.
+type RowMarshaler interface {
+	// CSVHeader returns the column names. It is called on the zero value.
+	CSVHeader() []string
+
+	// MarshalCSVRow appends the value of each column to row.
+	MarshalCSVRow(row []string) ([]string, error)
+}
+
+// ColumnsMarshaler is implemented by field types which encode to several
+// columns, such as a type whose UnmarshalCSV reads several columns of the Row.
+// Its columns take the place of the field's own in the header. A field whose
+// own column is missing from a document is decoded from the first of its
+// columns instead.
+type ColumnsMarshaler interface {
+	// CSVColumns returns the column names. It is called on the zero value.
+	CSVColumns() []string
+
+	// MarshalCSVColumns returns the value of each column.
+	MarshalCSVColumns() ([]string, error)
+}
+
 type encoder struct {
 	*csv.Writer
-	buffer *bytes.Buffer
+	w       io.Writer   // the output under the csv.Writer
+	opts    *options    // the options given to Marshal or WriteAll
+	plan    *encodePlan // the plan for the slice's element type
+	row     []string    // reused for each encoded row
+	errs    []error     // the collected row errors
+	protect bool        // cells are protected from conversion by Excel
 }
 
 // Marshal returns the CSV encoding of i, which must be a slice of struct types.
@@ -35,13 +66,41 @@ type encoder struct {
 //
 // Boolean fields can use string values to define true or false.
 //   Bool bool `true:"Yes" false:"No"`
-func Marshal(i interface{}) ([]byte, error) {
-	// validate the interface
-	// create a new encoder
-	//   assing the cfields
-	// get the headers
-	// encoder each row
-
+//
+// time.Time fields use the layout tag, or time.RFC3339. The zero time is an
+// empty cell, as is a nil pointer of any type.
+//
+//   Joined time.Time `layout:"01/02/2006"`
+//   Score  *int
+//
+// Float fields are written in the shortest 'g' format unless the FloatFormat
+// option is given. A field can use a fmt verb, or the fmt and precision
+// arguments of strconv.FormatFloat.
+//
+//   Price float64 `format:"%.2f"`
+//   Rate  float64 `fmt:"f" precision:"4"`
+//
+// Complex fields are written with three significant digits by default. The
+// same tags apply to them, and a precision of -1 round trips exactly.
+//
+//   Wave complex128 `precision:"-1"` // (1.0000001+2i)
+//
+// Numeric fields can use the separators of a locale, or set them directly.
+// The same tags are used by Unmarshal.
+//
+//   Income int     `locale:"en"`           // 32,000
+//   Amount float64 `decimal:"," group:"."` // 1.234,56
+//
+// A currency symbol is written before the number, or after it when the tag
+// starts with a space. The accounting option writes negatives in parentheses
+// and the percent option writes a fraction as a percentage.
+//
+//   Price float64 `currency:"$" locale:"en" format:"%.2f" csv:",accounting"` // ($1,204.10)
+//   Cost  float64 `currency:" €" locale:"de"`                                // 1.204,1 €
+//   Rate  float64 `csv:",percent"`                                           // 12.5%
+//
+// opts change how the document is written, such as the delimiter.
+func Marshal(i interface{}, opts ...Option) ([]byte, error) {
 	data := reflect.ValueOf(i)
 
 	if data.Kind() != reflect.Slice {
@@ -54,37 +113,85 @@ func Marshal(i interface{}) ([]byte, error) {
 		return []byte{}, nil
 	}
 
-	el := data.Index(0)
-	if el.Kind() == reflect.Interface {
-		el = el.Elem()
-	}
-	enc, err := newEncoder(el)
+	b := bytes.NewBuffer([]byte{})
+	o := newOptions(opts)
+	err := marshal(b, data, o)
 
-	if err != nil {
+	// collected errors come with the rows which were written
+	if err != nil && !o.collect {
 		return []byte{}, err
 	}
 
-	err = enc.encodeAll(data)
+	return b.Bytes(), err
+}
+
+// marshal writes the header and then every element of the data slice to w.
+func marshal(w io.Writer, data reflect.Value, o *options) error {
+	t := data.Type().Elem()
+
+	// the columns of an interface slice come from its first element
+	if t.Kind() == reflect.Interface {
+		if data.Len() == 0 {
+			return nil
+		}
+		t = data.Index(0).Elem().Type()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("only slices of structs can be marshalled: %s", t)
+	}
+
+	if err := encodePlanFor(t).err; err != nil {
+		return err
+	}
+
+	w = o.encodeCharset(w)
+
+	if err := writePreamble(w, o); err != nil {
+		return err
+	}
+
+	enc := newEncoder(w, t, o)
+
+	if o.header() {
+		if err := enc.Write(enc.plan.cols); err != nil {
+			return err
+		}
+	}
+
+	var err error
+
+	if o.workers > 1 {
+		err = enc.encodeConcurrent(data)
+	} else {
+		err = enc.encodeAll(data, 0)
+	}
 
 	if err != nil {
-		return []byte{}, err
+		return err
 	}
 
 	enc.Flush()
-	return enc.buffer.Bytes(), nil
-}
 
-func newEncoder(el reflect.Value) (*encoder, error) {
-	b := bytes.NewBuffer([]byte{})
+	if err := enc.Error(); err != nil {
+		return err
+	}
 
+	return errors.Join(enc.errs...)
+}
+
+func newEncoder(w io.Writer, t reflect.Type, o *options) *encoder {
 	enc := &encoder{
-		buffer: b,
-		Writer: csv.NewWriter(b),
+		Writer:  csv.NewWriter(w),
+		w:       w,
+		opts:    o,
+		plan:    encodePlanFor(t),
+		protect: o.protect(),
 	}
 
-	err := enc.Write(colNames(el.Type()))
+	o.applyWriter(enc.Writer)
 
-	return enc, err
+	return enc
 }
 
 // colNames takes a struct and returns the computed columns names for each
@@ -95,7 +202,13 @@ func colNames(t reflect.Type) (out []string) {
 	for x := 0; x < l; x++ {
 		f := t.Field(x)
 		h, ok := fieldHeaderName(f)
-		if ok {
+		if !ok {
+			continue
+		}
+
+		if cols, multi := fieldColumns(f.Type); multi {
+			out = append(out, cols...)
+		} else {
 			out = append(out, h)
 		}
 	}
@@ -103,13 +216,31 @@ func colNames(t reflect.Type) (out []string) {
 	return
 }
 
-// encodeAll iterates over each item in data, encoder it then writes it
-func (enc *encoder) encodeAll(data reflect.Value) error {
+// encodeAll iterates over each item in data, encoder it then writes it. first
+// is the index of data's first item in the slice being marshalled.
+func (enc *encoder) encodeAll(data reflect.Value, first int) error {
 	n := data.Len()
 	for c := 0; c < n; c++ {
 		row, err := enc.encodeRow(data.Index(c))
 
+		if err == SkipRow {
+			continue
+		}
+
+		if err == nil && enc.protect {
+			for i, cell := range row {
+				row[i] = protect(cell)
+			}
+		}
+
 		if err != nil {
+			err = &EncodeError{Index: first + c, Err: err}
+
+			if enc.opts != nil && enc.opts.collect {
+				enc.errs = append(enc.errs, err)
+				continue
+			}
+
 			return err
 		}
 
@@ -123,75 +254,348 @@ func (enc *encoder) encodeAll(data reflect.Value) error {
 	return nil
 }
 
-// encodes a struct into a CSV row
+// encodes a struct into a CSV row. The returned row is reused by the next call.
 func (enc *encoder) encodeRow(v reflect.Value) ([]string, error) {
-
-	var row []string
-	// TODO env.columns should map to a cfield
-	// iterate over each cfield and encode with it
-
 	if v.Kind() == reflect.Interface {
 		v = v.Elem()
 	}
-	l := v.Type().NumField()
 
-	for x := 0; x < l; x++ {
-		fv := v.Field(x)
-		st := v.Type().Field(x).Tag
+	p := enc.plan
+	if v.Type() != p.Type {
+		p = encodePlanFor(v.Type())
+
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+
+	if p.before {
+		if err := beforeMarshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.rows {
+		row, err := v.Interface().(RowMarshaler).MarshalCSVRow(enc.row[:0])
+		enc.row = row
+		return row, err
+	}
+
+	enc.row = enc.row[:0]
+
+	for _, f := range p.fields {
+		fv := v.FieldByIndex(f.index)
+
+		if f.multi {
+			cells, err := marshalColumns(fv, len(f.columns))
+			if err != nil {
+				return nil, err
+			}
+			enc.row = append(enc.row, cells...)
+			continue
+		}
 
-		if st.Get("csv") == "-" {
+		if f.field != nil {
+			cell, err := marshalField(fv, *f.field)
+			if err != nil {
+				return nil, err
+			}
+			enc.row = append(enc.row, cell)
 			continue
 		}
-		o := enc.encodeCol(fv, st)
-		row = append(row, o)
+
+		enc.row = append(enc.row, f.encode(enc, fv))
 	}
 
-	return row, nil
+	return enc.row, nil
 }
 
 // Returns the string representation of the field value
-func (enc *encoder) encodeCol(fv reflect.Value, st reflect.StructTag) string {
-	switch fv.Kind() {
+func (enc *encoder) encodeCol(fv reflect.Value, st reflect.StructTag) (string, error) {
+	e, err := encoderFor(fv.Type(), st)
+
+	if err != nil {
+		return "", err
+	}
+
+	return e(enc, fv), nil
+}
+
+// encoderFn returns the string representation of a field value
+type encoderFn func(*encoder, reflect.Value) string
+
+var (
+	marshalerType        = reflect.TypeOf(new(Marshaler)).Elem()
+	columnsMarshalerType = reflect.TypeOf(new(ColumnsMarshaler)).Elem()
+)
+
+// fieldColumns returns the columns of a ColumnsMarshaler type, reporting if t
+// is one.
+func fieldColumns(t reflect.Type) ([]string, bool) {
+	if !implementsEither(t, columnsMarshalerType) {
+		return nil, false
+	}
+
+	v := reflect.New(t)
+
+	if t.Kind() == reflect.Ptr {
+		v.Elem().Set(reflect.New(t.Elem()))
+		v = v.Elem()
+	}
+
+	return v.Interface().(ColumnsMarshaler).CSVColumns(), true
+}
+
+// marshalColumns returns the n cells of a ColumnsMarshaler field. A nil
+// pointer is written as empty cells.
+func marshalColumns(fv reflect.Value, n int) ([]string, error) {
+	switch {
+	case fv.Kind() == reflect.Ptr && fv.IsNil():
+		return make([]string, n), nil
+	case fv.Kind() == reflect.Ptr:
+	case fv.CanAddr():
+		fv = fv.Addr()
+	default:
+		p := reflect.New(fv.Type())
+		p.Elem().Set(fv)
+		fv = p
+	}
+
+	cells, err := fv.Interface().(ColumnsMarshaler).MarshalCSVColumns()
+
+	if err == nil && len(cells) != n {
+		err = fmt.Errorf("%s returned %d columns, not %d", fv.Type(), len(cells), n)
+	}
+
+	return cells, err
+}
+
+// encoderFor resolves the encoderFn for a field of type t tagged with st. An
+// invalid tag is returned as an error.
+func encoderFor(t reflect.Type, st reflect.StructTag) (encoderFn, error) {
+	if e, err := bigEncoderFor(t, st); e != nil || err != nil {
+		return e, err
+	}
+
+	if t == timeType {
+		return encodeTime(st), nil
+	}
+
+	switch t.Kind() {
 	case reflect.String:
-		return fv.String()
+		return encodeString, nil
 	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8:
-		return fmt.Sprintf("%v", fv.Int())
-	case reflect.Float32:
-		return encodeFloat(32, fv)
-	case reflect.Float64:
-		return encodeFloat(64, fv)
+		return numeric(st, false, encodeInt)
+	case reflect.Float32, reflect.Float64:
+		e, err := encodeFloat(t.Bits(), st)
+		if err != nil {
+			return nil, err
+		}
+		return numeric(st, true, e)
 	case reflect.Bool:
-		return encodeBool(fv.Bool(), st)
+		return encodeBool(st), nil
 	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
-		return fmt.Sprintf("%v", fv.Uint())
+		return numeric(st, false, encodeUint)
 	case reflect.Complex64, reflect.Complex128:
-		return fmt.Sprintf("%+.3g", fv.Complex())
+		return encodeComplex(t.Bits(), st)
 	case reflect.Interface:
-		return encodeInterface(fv, st)
+		return encodeInterface, nil
 	case reflect.Struct:
-		return encodeInterface(fv, st)
+		return encodeInterface, nil
+	case reflect.Ptr:
+		return encodePointer(t, st)
 	default:
-		panic(fmt.Sprintf("Unsupported type %s", fv.Kind()))
+		return func(*encoder, reflect.Value) string {
+			panic(fmt.Sprintf("Unsupported type %s", t.Kind()))
+		}, nil
+	}
+}
+
+// numeric writes the number from e with the separators, symbols and sign of
+// the number format tags.
+func numeric(st reflect.StructTag, float bool, e encoderFn) (encoderFn, error) {
+	nf, err := numberFormatFor(st, float)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if nf.isZero() {
+		return e, nil
 	}
+
+	return func(enc *encoder, fv reflect.Value) string {
+		return nf.format(e(enc, fv))
+	}, nil
+}
+
+func encodeString(enc *encoder, fv reflect.Value) string {
+	return fv.String()
+}
+
+func encodeInt(enc *encoder, fv reflect.Value) string {
+	return strconv.FormatInt(fv.Int(), 10)
 }
 
-func encodeFloat(bits int, f reflect.Value) string {
-	return strconv.FormatFloat(f.Float(), 'g', -1, bits)
+func encodeUint(enc *encoder, fv reflect.Value) string {
+	return strconv.FormatUint(fv.Uint(), 10)
 }
 
-func encodeBool(b bool, st reflect.StructTag) string {
-	v := strconv.FormatBool(b)
-	tv := st.Get(v)
+// encodeComplex writes complex numbers with three significant digits, as in
+// (+1+2i), unless the format, fmt or precision tags or the FloatFormat option
+// are set. A precision of -1 writes them losslessly.
+func encodeComplex(bits int, st reflect.StructTag) (encoderFn, error) {
+	ff, err := floatFormatFor(st)
 
-	if tv != "" {
-		return tv
+	if err != nil {
+		return nil, err
+	}
+
+	if ff.verb != "" {
+		return func(enc *encoder, fv reflect.Value) string {
+			return fmt.Sprintf(ff.verb, fv.Complex())
+		}, nil
 	}
-	return v
+
+	return func(enc *encoder, fv reflect.Value) string {
+		if ff.fmt == 0 && !ff.hasPrec && (enc.opts == nil || !enc.opts.float.hasPrec) {
+			return fmt.Sprintf("%+.3g", fv.Complex())
+		}
+
+		f := ff.merge(enc.floatFormat())
+		return strconv.FormatComplex(fv.Complex(), f.fmt, f.prec, bits)
+	}, nil
 }
 
-func encodeInterface(fv reflect.Value, st reflect.StructTag) string {
-	marshalerType := reflect.TypeOf(new(Marshaler)).Elem()
+// encodeFloat writes floats with the format, fmt and precision tags. Without
+// tags the encoder's FloatFormat is used.
+func encodeFloat(bits int, st reflect.StructTag) (encoderFn, error) {
+	ff, err := floatFormatFor(st)
+
+	if err != nil {
+		return nil, err
+	}
+
+	percent := hasTagOption(st, "percent")
+
+	value := func(fv reflect.Value) float64 {
+		if percent {
+			return scalePercent(fv.Float(), bits)
+		}
+		return fv.Float()
+	}
+
+	if ff.verb != "" {
+		return func(enc *encoder, fv reflect.Value) string {
+			return fmt.Sprintf(ff.verb, value(fv))
+		}, nil
+	}
+
+	return func(enc *encoder, fv reflect.Value) string {
+		f := ff.merge(enc.floatFormat())
+		return strconv.FormatFloat(value(fv), f.fmt, f.prec, bits)
+	}, nil
+}
+
+// scalePercent returns the fraction f as a percentage. The shortest decimal
+// form of f is shifted, so 0.123 is 12.3 and not 12.299999999999999.
+func scalePercent(f float64, bits int) float64 {
+	s := shiftPoint(strconv.FormatFloat(f, 'g', -1, bits), 2)
+	p, _ := strconv.ParseFloat(s, 64)
+
+	return p
+}
+
+// floatFormat is a format given to strconv.FormatFloat, or a fmt verb such as
+// "%.2f". A zero fmt or unset prec defers to another floatFormat.
+type floatFormat struct {
+	verb    string
+	fmt     byte
+	prec    int
+	hasPrec bool
+}
+
+var defaultFloatFormat = floatFormat{fmt: 'g', prec: -1, hasPrec: true}
+
+// floatFormatFor reads the format, fmt and precision tags.
+func floatFormatFor(st reflect.StructTag) (floatFormat, error) {
+	ff := floatFormat{verb: st.Get("format")}
+
+	if f := st.Get("fmt"); f != "" {
+		if len(f) != 1 || !strings.Contains("bgeEfGxX", f) {
+			return ff, fmt.Errorf("invalid fmt tag %q", f)
+		}
+		ff.fmt = f[0]
+	}
+
+	if p := st.Get("precision"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return ff, fmt.Errorf("invalid precision tag %q", p)
+		}
+		ff.prec, ff.hasPrec = n, true
+	}
+
+	return ff, nil
+}
+
+// merge fills the unset parts of ff from d
+func (ff floatFormat) merge(d floatFormat) floatFormat {
+	if ff.fmt == 0 {
+		ff.fmt = d.fmt
+	}
+
+	if !ff.hasPrec {
+		ff.prec, ff.hasPrec = d.prec, d.hasPrec
+	}
+
+	return ff
+}
+
+// floatFormat returns the FloatFormat option or the default 'g' format
+func (enc *encoder) floatFormat() floatFormat {
+	if enc.opts == nil {
+		return defaultFloatFormat
+	}
+
+	return enc.opts.float.merge(defaultFloatFormat)
+}
+
+// encodeBool uses the true and false tags when they are set
+func encodeBool(st reflect.StructTag) encoderFn {
+	tokens := [2]string{"false", "true"}
+
+	for i, v := range tokens {
+		if tv := st.Get(v); tv != "" {
+			tokens[i] = tv
+		}
+	}
+
+	return func(enc *encoder, fv reflect.Value) string {
+		if fv.Bool() {
+			return tokens[1]
+		}
+		return tokens[0]
+	}
+}
+
+// encodePointer writes a nil pointer as an empty cell
+func encodePointer(t reflect.Type, st reflect.StructTag) (encoderFn, error) {
+	e, err := encoderFor(t.Elem(), st)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(enc *encoder, fv reflect.Value) string {
+		if fv.IsNil() {
+			return ""
+		}
+		return e(enc, fv.Elem())
+	}, nil
+}
 
+func encodeInterface(enc *encoder, fv reflect.Value) string {
 	if fv.Type().Implements(marshalerType) {
 		m := fv.Interface().(Marshaler)
 		b, err := m.MarshalCSV()
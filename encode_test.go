@@ -2,7 +2,9 @@ package csv
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -40,8 +42,32 @@ func TestEncodeFieldValue(t *testing.T) {
 		// Numerics
 		{int(1), "1", ""},
 		{float32(3.2), "3.2", ""},
+		{float64(12000000), "1.2e+07", ""},
+		{float64(12000000), "12000000.00", `format:"%.2f"`},
+		{float64(3.14159), "3.1416", `fmt:"f" precision:"4"`},
+		{float32(0.5), "5.00e-01", `fmt:"e" precision:"2"`},
+		{float64(12000000), "12000000", `fmt:"f"`},
+		{int(-32000), "-32,000", `locale:"en"`},
+		{uint(1234567), "1.234.567", `locale:"de"`},
+		{float64(1234.5), "1.234,50", `locale:"de" format:"%.2f"`},
+		{float64(1234.5), "1 234,5", `decimal:"," group:" "`},
+		{float64(123), "123", `locale:"de"`},
+		{float64(1.5e21), "1,5e+21", `locale:"de"`},
+		{float64(1204.1), "$1,204.10", `currency:"$" locale:"en" format:"%.2f"`},
+		{float64(-45), "($45.00)", `currency:"$" format:"%.2f" csv:",accounting"`},
+		{float64(-1204.1), "($1,204.10)", `currency:"$" locale:"en" format:"%.2f" csv:",accounting"`},
+		{float64(-45), "-$45", `currency:"$"`},
+		{float64(1204.1), "1.204,1 €", `currency:" €" locale:"de"`},
+		{float64(0.123), "12.3%", `csv:",percent"`},
+		{float32(0.125), "12.50%", `csv:",percent" fmt:"f" precision:"2"`},
+		{float64(-0.00015), "-0.015%", `csv:",percent"`},
+		{int(-7), "(7)", `csv:",accounting"`},
 		{uint32(123), "123", ""},
 		{complex64(1 + 2i), "(+1+2i)", ""},
+		{complex128(1.0000001 + 2i), "(+1+2i)", ""},
+		{complex128(1.0000001 + 2i), "(1.0000001+2i)", `precision:"-1"`},
+		{complex64(0.5 - 1i), "(0.50-1.00i)", `fmt:"f" precision:"2"`},
+		{complex64(0.5 - 1i), "(0.5-1i)", `format:"%v"`},
 
 		// Boolean
 		{true, "Yes", `true:"Yes" false:"No"`},
@@ -60,9 +86,9 @@ func TestEncodeFieldValue(t *testing.T) {
 	for _, test := range encTests {
 		fv := reflect.ValueOf(test.val)
 		st := reflect.StructTag(test.tag)
-		res := enc.encodeCol(fv, st)
+		res, err := enc.encodeCol(fv, st)
 
-		if res != test.expected {
+		if err != nil || res != test.expected {
 			t.Errorf("%s does not match %s", res, test.expected)
 		}
 	}
@@ -108,3 +134,142 @@ Value 1
 		t.Fail()
 	}
 }
+
+type rowM struct {
+	A, B int
+}
+
+func (r rowM) CSVHeader() []string {
+	return []string{"Sum"}
+}
+
+func (r rowM) MarshalCSVRow(row []string) ([]string, error) {
+	return append(row, fmt.Sprint(r.A+r.B)), nil
+}
+
+func (r *rowM) UnmarshalCSVRow(row *Row) error {
+	v, err := row.Named("Sum")
+	r.A, r.B = len(v), -1
+	return err
+}
+
+func TestRowMarshaler(t *testing.T) {
+	out, err := Marshal([]rowM{{1, 2}, {3, 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "Sum\n3\n7\n" {
+		t.Errorf("RowMarshaler was not used %q", out)
+	}
+
+	rr := []rowM{}
+	if err := Unmarshal(out, &rr); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rr) != 2 || rr[0] != (rowM{1, -1}) {
+		t.Errorf("RowUnmarshaler was not used %v", rr)
+	}
+}
+
+func TestFloatFormat(t *testing.T) {
+	type F struct {
+		A float64
+		B float64 `precision:"1"`
+		C float64 `format:"%.3f"`
+	}
+
+	out, err := Marshal([]F{{12000000, 2.25, 1}}, FloatFormat('f', 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "A,B,C\n12000000.00,2.2,1.000\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	type Bad struct {
+		A float64 `fmt:"z"`
+	}
+
+	if _, err := Marshal([]Bad{{1}}); err == nil {
+		t.Error("no error for an invalid fmt tag")
+	}
+
+	type BadComplex struct {
+		A *complex128 `precision:"x"`
+	}
+
+	var b bytes.Buffer
+	if err := WriteAll(&b, []BadComplex{{}}); err == nil || b.Len() != 0 {
+		t.Errorf("no error for an invalid precision tag %v %q", err, b.Bytes())
+	}
+}
+
+type point struct {
+	X, Y int
+}
+
+func (p point) CSVColumns() []string {
+	return []string{"X", "Y"}
+}
+
+func (p point) MarshalCSVColumns() ([]string, error) {
+	if p.X < 0 {
+		return []string{"bad"}, nil
+	}
+	return []string{strconv.Itoa(p.X), strconv.Itoa(p.Y)}, nil
+}
+
+func (p *point) UnmarshalCSV(val string, row *Row) error {
+	x, _ := row.Named("X")
+	y, _ := row.Named("Y")
+	p.X, _ = strconv.Atoi(x)
+	p.Y, _ = strconv.Atoi(y)
+	return nil
+}
+
+func TestColumnsMarshaler(t *testing.T) {
+	type shape struct {
+		Name string
+		At   point
+	}
+
+	in := []shape{{"a", point{1, 2}}, {"b", point{3, 4}}}
+
+	out, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "Name,X,Y\na,1,2\nb,3,4\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	ss := []shape{}
+	if err := Unmarshal(out, &ss); err != nil {
+		t.Fatal(err)
+	}
+
+	if ss[1].At != in[1].At {
+		t.Errorf("columns did not round trip %v", ss)
+	}
+
+	if _, err := Marshal([]shape{{At: point{-1, 0}}}); err == nil {
+		t.Error("no error for the wrong number of columns")
+	}
+
+	type scaled struct {
+		Scale *point
+	}
+
+	out, err = Marshal([]scaled{{nil}, {&point{5, 6}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "X,Y\n,\n5,6\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+}
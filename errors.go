@@ -0,0 +1,38 @@
+package csv
+
+import (
+	"fmt"
+)
+
+// DecodeError reports where a record could not be decoded.
+type DecodeError struct {
+	Line   int    // the 1-based line of the record
+	Column string // the column name, empty when the whole row failed
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// EncodeError reports which element of a slice could not be encoded.
+type EncodeError struct {
+	Index int // the index of the element in the slice
+	Err   error
+}
+
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("element %d: %v", e.Index, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
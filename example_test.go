@@ -16,6 +16,16 @@ type Address struct {
 	Street string
 }
 
+// CSVColumns and MarshalCSVColumns write Address to the columns which
+// UnmarshalCSV reads.
+func (a Address) CSVColumns() []string {
+	return []string{"City", "Street"}
+}
+
+func (a Address) MarshalCSVColumns() ([]string, error) {
+	return []string{a.City, a.Street}, nil
+}
+
 func (a *Address) UnmarshalCSV(val string, row *Row) error {
 	c, _ := row.Named("City")
 	s, _ := row.Named("Street")
@@ -45,3 +55,26 @@ John Doe,"32,000",45,Brooklyn,"7th Street"
 	// Output:
 	// [{Name:John Doe income: Age:0 Address:{City:Brooklyn Street:7th Street}}]
 }
+
+func ExampleColumnsMarshaler() {
+	people := []Person{}
+
+	sample := []byte(
+		`Full Name,City,Street
+John Doe,Brooklyn,"7th Street"
+`)
+
+	Unmarshal(sample, &people)
+
+	out, err := Marshal(people)
+
+	if err != nil {
+		fmt.Println("Error: ", err)
+	}
+
+	fmt.Printf("%s", out)
+
+	// Output:
+	// Full Name,income,City,Street
+	// John Doe,,Brooklyn,7th Street
+}
@@ -0,0 +1,94 @@
+package csv
+
+import (
+	"reflect"
+)
+
+// Field describes the column a FieldUnmarshaler is decoded from or a
+// FieldMarshaler is encoded to.
+type Field struct {
+	Name    string            // the column name
+	Index   int               // the column's position in the record
+	Line    int               // the 1-based line of the record, 0 when encoding
+	Tag     reflect.StructTag // the struct field's whole tag
+	Options []string          // the options of the csv tag after the name, such as percent
+}
+
+// FieldUnmarshaler is an Unmarshaler which also receives the Field it is
+// decoded from, so one type can honour a format set by each field's tag.
+//
+//	Born Date `layout:"02/01/2006"`
+//
+// It is preferred over Unmarshaler.
+type FieldUnmarshaler interface {
+	UnmarshalCSVField(val string, f Field, row *Row) error
+}
+
+// FieldMarshaler is a Marshaler which also receives the Field it is encoded
+// to. It is preferred over Marshaler, and unlike Marshaler its errors stop
+// encoding.
+type FieldMarshaler interface {
+	MarshalCSVField(f Field) ([]byte, error)
+}
+
+var (
+	fieldUnmarshalerType = reflect.TypeOf(new(FieldUnmarshaler)).Elem()
+	fieldMarshalerType   = reflect.TypeOf(new(FieldMarshaler)).Elem()
+)
+
+// newField returns the Field of the struct field sf in column index.
+func newField(sf *reflect.StructField, name string, index int) Field {
+	_, opts := splitTag(sf.Tag)
+
+	return Field{Name: name, Index: index, Tag: sf.Tag, Options: opts}
+}
+
+func (cf *cfield) assignFieldUnmarshaller(code int, f Field) {
+	cf.field = f
+
+	if code == impsPtr {
+		cf.decoder = cf.unmarshalFieldPointer
+	} else {
+		cf.decoder = cf.unmarshalFieldValue
+	}
+}
+
+func (cf *cfield) unmarshalFieldPointer(cell reflect.Value, row *Row) error {
+	return cf.unmarshalField(cell.Addr().Interface().(FieldUnmarshaler), row)
+}
+
+func (cf *cfield) unmarshalFieldValue(cell reflect.Value, row *Row) error {
+	return cf.unmarshalField(cell.Interface().(FieldUnmarshaler), row)
+}
+
+func (cf *cfield) unmarshalField(m FieldUnmarshaler, row *Row) error {
+	f := cf.field
+	f.Line = row.Line
+
+	return m.UnmarshalCSVField(row.At(cf.colIndex), f, row)
+}
+
+// marshalField returns the cell of a FieldMarshaler field. A nil pointer is
+// written as an empty cell.
+func marshalField(fv reflect.Value, f Field) (string, error) {
+	if fv.Kind() == reflect.Ptr && fv.IsNil() {
+		return "", nil
+	}
+
+	if fv.Kind() != reflect.Ptr && fv.CanAddr() {
+		fv = fv.Addr()
+	}
+
+	m, ok := fv.Interface().(FieldMarshaler)
+
+	if !ok {
+		// a pointer method on a value which is not addressable
+		p := reflect.New(fv.Type())
+		p.Elem().Set(fv)
+		m = p.Interface().(FieldMarshaler)
+	}
+
+	b, err := m.MarshalCSVField(f)
+
+	return string(b), err
+}
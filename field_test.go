@@ -0,0 +1,95 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// day reads and writes dates in the layout of its field's tag
+type day struct {
+	time.Time
+}
+
+func (d *day) UnmarshalCSVField(val string, f Field, row *Row) error {
+	t, err := time.Parse(f.Tag.Get("layout"), val)
+	d.Time = t
+	return err
+}
+
+func (d day) MarshalCSVField(f Field) ([]byte, error) {
+	if d.IsZero() {
+		return nil, errors.New("no date in column " + f.Name)
+	}
+	return []byte(d.Format(f.Tag.Get("layout"))), nil
+}
+
+// probe records the Field it is given
+type probe struct {
+	f Field
+}
+
+func (p *probe) UnmarshalCSVField(val string, f Field, row *Row) error {
+	p.f = f
+	return nil
+}
+
+func TestFieldUnmarshaler(t *testing.T) {
+	type D struct {
+		Name  string
+		Born  day   `layout:"02/01/2006"`
+		Died  day   `csv:"Death" layout:"2006-01-02"`
+		Probe probe `csv:"P,accounting,percent"`
+	}
+
+	doc := []byte(`Name,Born,Death,P
+Ada,10/12/1815,1852-11-27,
+`)
+
+	dd := []D{}
+	if err := Unmarshal(doc, &dd); err != nil {
+		t.Fatal(err)
+	}
+
+	if dd[0].Born.Year() != 1815 || dd[0].Born.Month() != 12 || dd[0].Died.Year() != 1852 {
+		t.Errorf("incorrect dates %v", dd)
+	}
+
+	expected := Field{Name: "P", Index: 3, Line: 2, Tag: `csv:"P,accounting,percent"`, Options: []string{"accounting", "percent"}}
+	if !reflect.DeepEqual(dd[0].Probe.f, expected) {
+		t.Errorf("incorrect field %+v", dd[0].Probe.f)
+	}
+
+	type E struct {
+		Name string
+		Born day `layout:"02/01/2006"`
+		Died day `csv:"Death" layout:"2006-01-02"`
+	}
+
+	out, err := Marshal([]E{{dd[0].Name, dd[0].Born, dd[0].Died}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "Name,Born,Death\nAda,10/12/1815,1852-11-27\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	_, err = Marshal([]E{{Name: "Bob"}})
+
+	var ee *EncodeError
+	if !errors.As(err, &ee) || err.Error() != "element 0: no date in column Born" {
+		t.Errorf("expected an EncodeError got %v", err)
+	}
+	// a nil pointer with a value receiver is an empty cell
+	type P struct {
+		Name string
+		Born *day `layout:"02/01/2006"`
+	}
+
+	out, err = Marshal([]P{{"Ada", &dd[0].Born}, {Name: "Bob"}})
+	if err != nil || string(out) != "Name,Born\nAda,10/12/1815\nBob,\n" {
+		t.Errorf("incorrect output %q %v", out, err)
+	}
+}
@@ -0,0 +1,92 @@
+package csv
+
+import (
+	"io"
+	"iter"
+	"reflect"
+)
+
+// ReadAll decodes every record in r into a slice of T. T follows the same
+// rules as the slice element given to Unmarshal.
+func ReadAll[T any](r io.Reader, opts ...Option) ([]T, error) {
+	out := []T{}
+
+	dec, err := newDecoder(r, reflect.TypeFor[T](), opts)
+
+	if err != nil {
+		return out, err
+	}
+
+	err = dec.unmarshal(reflect.ValueOf(&out).Elem())
+
+	return out, err
+}
+
+// Reader decodes one record at a time into values of type T.
+type Reader[T any] struct {
+	r    io.Reader
+	opts []Option
+	dec  *decoder
+	err  error
+}
+
+// NewReader returns a Reader decoding from r. The header line is read by the
+// first call to Read.
+func NewReader[T any](r io.Reader, opts ...Option) *Reader[T] {
+	return &Reader[T]{r: r, opts: opts}
+}
+
+// Read decodes the next record. It returns io.EOF when there are no more
+// records. On error the zero value of T is returned, and an error reading the
+// header is returned by every call. Rows skipped by AfterUnmarshalCSV are
+// passed over, and each row's error is returned even with CollectErrors.
+func (r *Reader[T]) Read() (T, error) {
+	var v T
+
+	if r.dec == nil && r.err == nil {
+		r.dec, r.err = newDecoder(r.r, reflect.TypeFor[T](), r.opts)
+	}
+
+	if r.err != nil {
+		return v, r.err
+	}
+
+	el := reflect.ValueOf(&v).Elem()
+	err := r.dec.decodeNext(&el)
+
+	for err == SkipRow {
+		el.SetZero()
+		err = r.dec.decodeNext(&el)
+	}
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return v, nil
+}
+
+// All returns an iterator over the remaining records. Iteration stops after
+// the first error, which is yielded with the zero value of T.
+func (r *Reader[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			v, err := r.Read()
+
+			if err == io.EOF {
+				return
+			}
+
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// WriteAll encodes the header and every value in data to w. Unlike Marshal the
+// header is written for an empty slice.
+func WriteAll[T any](w io.Writer, data []T, opts ...Option) error {
+	return marshal(w, reflect.ValueOf(data), newOptions(opts))
+}
@@ -0,0 +1,99 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type gen struct {
+	Name string
+	Age  int
+}
+
+const genDoc = `Name,Age
+Jay,23
+Kay,31
+`
+
+func TestReadAll(t *testing.T) {
+	gg, err := ReadAll[gen](strings.NewReader(genDoc))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gg) != 2 || gg[1].Name != "Kay" || gg[1].Age != 31 {
+		t.Errorf("incorrect results %v", gg)
+	}
+
+	_, err = ReadAll[int](strings.NewReader(genDoc))
+
+	if err == nil {
+		t.Error("no error for a non struct type")
+	}
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader[gen](strings.NewReader(genDoc))
+
+	g, err := r.Read()
+	if err != nil || g.Name != "Jay" {
+		t.Errorf("incorrect first read %v %v", g, err)
+	}
+
+	g, err = r.Read()
+	if err != nil || g.Name != "Kay" {
+		t.Errorf("incorrect second read %v %v", g, err)
+	}
+
+	_, err = r.Read()
+	if err != io.EOF {
+		t.Errorf("expected EOF got %v", err)
+	}
+}
+
+func TestReaderAll(t *testing.T) {
+	doc := genDoc + "Bill,old\n"
+	names := []string{}
+
+	var last error
+	for g, err := range NewReader[gen](strings.NewReader(doc)).All() {
+		if err != nil {
+			last = err
+			continue
+		}
+		names = append(names, g.Name)
+	}
+
+	if strings.Join(names, " ") != "Jay Kay" {
+		t.Errorf("incorrect names %v", names)
+	}
+
+	if last == nil {
+		t.Error("decode error was not yielded")
+	}
+}
+
+func TestWriteAll(t *testing.T) {
+	gg := []gen{{"Jay", 23}, {"Kay", 31}}
+	b := &bytes.Buffer{}
+
+	if err := WriteAll(b, gg); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.String() != genDoc {
+		t.Errorf("incorrect output %q", b.String())
+	}
+
+	b.Reset()
+	if err := WriteAll(b, []gen{}, Comma(';')); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.String() != "Name;Age\n" {
+		t.Errorf("incorrect header %q", b.String())
+	}
+}
@@ -0,0 +1,151 @@
+// Code generated by csvgen. DO NOT EDIT.
+
+package csv_test
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jweir/csv"
+)
+
+// CSVHeader returns the column names of GenPrice.
+func (GenPrice) CSVHeader() []string {
+	return []string{"DeliveryDate", "HourEnding", "BusName", "LMP", "DSTFlag"}
+}
+
+// MarshalCSVRow appends the columns of t to row.
+func (t GenPrice) MarshalCSVRow(row []string) ([]string, error) {
+	row = append(row, t.DeliveryDate)
+	row = append(row, t.HourEnding)
+	row = append(row, t.BusName)
+	row = append(row, strconv.FormatFloat(float64(t.LMP), 'g', -1, 32))
+	if t.DSTFlag {
+		row = append(row, "Y")
+	} else {
+		row = append(row, "N")
+	}
+	return row, nil
+}
+
+// UnmarshalCSVRow sets the fields of t from the matching columns of row.
+func (t *GenPrice) UnmarshalCSVRow(row *csv.Row) error {
+	if v, err := row.Named("DeliveryDate"); err == nil {
+		t.DeliveryDate = v
+	}
+	if v, err := row.Named("HourEnding"); err == nil {
+		t.HourEnding = v
+	}
+	if v, err := row.Named("BusName"); err == nil {
+		t.BusName = v
+	}
+	if v, err := row.Named("LMP"); err == nil {
+		n, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return err
+		}
+		t.LMP = float32(n)
+	}
+	if v, err := row.Named("DSTFlag"); err == nil {
+		t.DSTFlag = v == "Y" || v != "N"
+	}
+	return nil
+}
+
+// CSVHeader returns the column names of GenMixed.
+func (GenMixed) CSVHeader() []string {
+	return []string{"Full Name", "Code", "Count", "Total", "Ratio", "Price", "Rate", "Ready", "Wait", "C64", "Phase", "Spot", "private"}
+}
+
+// MarshalCSVRow appends the columns of t to row.
+func (t GenMixed) MarshalCSVRow(row []string) ([]string, error) {
+	row = append(row, t.Name)
+	row = append(row, string(t.Code))
+	row = append(row, strconv.FormatInt(int64(t.Count), 10))
+	row = append(row, strconv.FormatUint(uint64(t.Total), 10))
+	row = append(row, strconv.FormatFloat(float64(t.Ratio), 'g', -1, 64))
+	row = append(row, fmt.Sprintf("%.2f", float64(t.Price)))
+	row = append(row, strconv.FormatFloat(float64(t.Rate), 'f', 3, 32))
+	if t.Ready {
+		row = append(row, "true")
+	} else {
+		row = append(row, "false")
+	}
+	row = append(row, strconv.FormatInt(int64(t.Wait), 10))
+	row = append(row, fmt.Sprintf("%+.3g", complex128(t.Wave)))
+	row = append(row, strconv.FormatComplex(complex128(t.Phase), 'g', -1, 128))
+	if b, err := t.Spot.MarshalCSV(); err == nil {
+		row = append(row, string(b))
+	} else {
+		row = append(row, "")
+	}
+	row = append(row, strconv.FormatInt(int64(t.private), 10))
+	return row, nil
+}
+
+// UnmarshalCSVRow sets the fields of t from the matching columns of row.
+func (t *GenMixed) UnmarshalCSVRow(row *csv.Row) error {
+	if v, err := row.Named("Full Name"); err == nil {
+		t.Name = v
+	}
+	if v, err := row.Named("Code"); err == nil {
+		t.Code = Code(v)
+	}
+	if v, err := row.Named("Count"); err == nil {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		t.Count = int8(n)
+	}
+	if v, err := row.Named("Ratio"); err == nil {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		t.Ratio = n
+	}
+	if v, err := row.Named("Price"); err == nil {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		t.Price = n
+	}
+	if v, err := row.Named("Rate"); err == nil {
+		n, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return err
+		}
+		t.Rate = float32(n)
+	}
+	if v, err := row.Named("Ready"); err == nil {
+		t.Ready = v == "" || v != ""
+	}
+	if v, err := row.Named("Wait"); err == nil {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		t.Wait = time.Duration(n)
+	}
+	if v, err := row.Named("C64"); err == nil {
+		c, err := strconv.ParseComplex(v, 64)
+		if err != nil {
+			return err
+		}
+		t.Wave = complex64(c)
+	}
+	if v, err := row.Named("Phase"); err == nil {
+		c, err := strconv.ParseComplex(v, 128)
+		if err != nil {
+			return err
+		}
+		t.Phase = c
+	}
+	t.Line = row.Line
+	t.Offset = row.Offset
+	t.Record = append([]string(nil), row.Data...)
+	return nil
+}
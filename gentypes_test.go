@@ -0,0 +1,51 @@
+package csv_test
+
+import "time"
+
+// Types with methods written by cmd/csvgen, checked against the reflective
+// encoder and decoder in csvgen_test.go.
+
+//go:generate go run ./cmd/csvgen -type GenPrice,GenMixed gentypes_test.go
+
+type GenPrice struct {
+	DeliveryDate string
+	HourEnding   string
+	BusName      string
+	LMP          float32
+	DSTFlag      bool `true:"Y" false:"N"`
+}
+
+type Code string
+
+type Spot struct {
+	Lat, Long string
+}
+
+func (s Spot) MarshalCSV() ([]byte, error) {
+	return []byte(s.Lat + " " + s.Long), nil
+}
+
+type GenMixed struct {
+	Name    string `csv:"Full Name"`
+	Code    Code
+	Count   int8
+	Total   uint64
+	Ratio   float64
+	Price   float64 `format:"%.2f"`
+	Rate    float32 `fmt:"f" precision:"3"`
+	Ready   bool
+	Wait    time.Duration
+	Wave    complex64  `csv:"C64"`
+	Phase   complex128 `precision:"-1"`
+	Spot    Spot
+	Skipped string `csv:"-"`
+	private int
+	Line    int      `csv:",line"`
+	Offset  int64    `csv:",offset"`
+	Record  []string `csv:",raw"`
+}
+
+// GenLocale can not be generated, see TestGenerateErrors.
+type GenLocale struct {
+	Income int `locale:"en"`
+}
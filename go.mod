@@ -0,0 +1,3 @@
+module github.com/jweir/csv
+
+go 1.23
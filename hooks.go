@@ -0,0 +1,92 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+)
+
+// AfterUnmarshaler is implemented by types which check or complete a value
+// once its fields are decoded, such as validating one field against another
+// or setting derived fields. Returning SkipRow drops the row.
+type AfterUnmarshaler interface {
+	AfterUnmarshalCSV(*Row) error
+}
+
+// BeforeMarshaler is implemented by types which check or prepare a value
+// before it is encoded. Returning SkipRow leaves the row out.
+type BeforeMarshaler interface {
+	BeforeMarshalCSV() error
+}
+
+var (
+	afterUnmarshalerType = reflect.TypeOf(new(AfterUnmarshaler)).Elem()
+	beforeMarshalerType  = reflect.TypeOf(new(BeforeMarshaler)).Elem()
+)
+
+// afterUnmarshal calls the element's AfterUnmarshalCSV. SkipRow is returned
+// as is, other errors as a DecodeError.
+func (dec *decoder) afterUnmarshal(row *Row, el *reflect.Value) error {
+	err := el.Addr().Interface().(AfterUnmarshaler).AfterUnmarshalCSV(row)
+
+	if err == nil || err == SkipRow {
+		return err
+	}
+
+	return &DecodeError{Line: row.Line, Err: err}
+}
+
+// keep reports if a decoded row is kept. SkipRow drops the row, as does a
+// DecodeError when errors are collected, which is appended to errs. Other
+// errors are returned.
+func (dec *decoder) keep(err error, errs *[]error) (bool, error) {
+	switch err.(type) {
+	case nil:
+		return true, nil
+	case *DecodeError:
+		if dec.collect {
+			*errs = append(*errs, err)
+			return false, nil
+		}
+	}
+
+	if err == SkipRow {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// collected returns the collected row errors joined together, or nil.
+func (dec *decoder) collected() error {
+	return errors.Join(dec.errs...)
+}
+
+// beforeMarshal calls v's BeforeMarshalCSV. A value which is not addressable,
+// such as an interface slice's element, is copied so a pointer receiver is
+// found.
+func beforeMarshal(v reflect.Value) error {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Ptr:
+	case v.CanAddr():
+		v = v.Addr()
+	default:
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p
+	}
+
+	if m, ok := v.Interface().(BeforeMarshaler); ok {
+		return m.BeforeMarshalCSV()
+	}
+
+	return nil
+}
+
+// implementsEither reports if t or *t implements the interface it.
+func implementsEither(t, it reflect.Type) bool {
+	return t.Implements(it) || reflect.PointerTo(t).Implements(it)
+}
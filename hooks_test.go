@@ -0,0 +1,139 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type span struct {
+	Start int
+	End   int
+	Len   int `csv:"-"`
+}
+
+func (s *span) AfterUnmarshalCSV(row *Row) error {
+	switch {
+	case s.Start < 0:
+		return SkipRow
+	case s.End < s.Start:
+		return errors.New("end is before start")
+	}
+
+	s.Len = s.End - s.Start
+	return nil
+}
+
+func (s *span) BeforeMarshalCSV() error {
+	switch {
+	case s.Start < 0:
+		return SkipRow
+	case s.End < s.Start:
+		return errors.New("end is before start")
+	}
+
+	return nil
+}
+
+var spanDoc = []byte(`Start,End
+1,4
+-1,0
+5,2
+2,x
+3,3
+`)
+
+func TestAfterUnmarshal(t *testing.T) {
+	ss := []span{}
+	err := Unmarshal(spanDoc, &ss)
+
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 4 || de.Column != "" {
+		t.Errorf("expected a DecodeError on line 4 got %v", err)
+	}
+
+	if !reflect.DeepEqual(ss, []span{{1, 4, 3}}) {
+		t.Errorf("incorrect rows %v", ss)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	expected := []span{{1, 4, 3}, {3, 3, 0}}
+
+	check := func(name string, ss []span, err error) {
+		t.Helper()
+
+		if !reflect.DeepEqual(ss, expected) {
+			t.Errorf("%s: incorrect rows %v", name, ss)
+		}
+
+		var lines []int
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			lines = append(lines, e.(*DecodeError).Line)
+		}
+
+		if !reflect.DeepEqual(lines, []int{4, 5}) {
+			t.Errorf("%s: incorrect errors %v", name, err)
+		}
+	}
+
+	for _, opts := range [][]Option{
+		{CollectErrors()},
+		{CollectErrors(), ReuseRecord()},
+		{CollectErrors(), Concurrency(2)},
+	} {
+		ss := []span{}
+		err := Unmarshal(spanDoc, &ss, opts...)
+		check("Unmarshal", ss, err)
+	}
+
+	ss := []span{}
+	err := Each(bytes.NewReader(spanDoc), func(s *span) error {
+		ss = append(ss, *s)
+		return nil
+	}, CollectErrors())
+	check("Each", ss, err)
+
+	if err := Unmarshal([]byte("Start,End\n1,2,3\n"), &ss, CollectErrors()); err == nil {
+		t.Error("a csv.Reader error was collected")
+	}
+}
+
+func TestReaderSkipsRows(t *testing.T) {
+	r := NewReader[span](strings.NewReader("Start,End\n-1,0\n2,5\n"))
+
+	s, err := r.Read()
+	if err != nil || s != (span{2, 5, 3}) {
+		t.Errorf("incorrect row %v %v", s, err)
+	}
+}
+
+func TestBeforeMarshal(t *testing.T) {
+	ss := []span{{1, 4, 0}, {-1, 0, 0}, {5, 2, 0}, {3, 3, 0}}
+
+	_, err := Marshal(ss)
+
+	var ee *EncodeError
+	if !errors.As(err, &ee) || ee.Index != 2 {
+		t.Errorf("expected an EncodeError for element 2 got %v", err)
+	}
+
+	// the elements of an interface slice are not addressable
+	if _, err := Marshal([]interface{}{span{5, 2, 0}}); !errors.As(err, &ee) {
+		t.Errorf("expected an EncodeError for an interface element got %v", err)
+	}
+
+	for _, opts := range [][]Option{{CollectErrors()}, {CollectErrors(), Concurrency(2)}} {
+		out, err := Marshal(ss, opts...)
+
+		if !errors.As(err, &ee) || ee.Index != 2 {
+			t.Errorf("expected a collected EncodeError got %v", err)
+		}
+
+		if string(out) != "Start,End\n1,4\n3,3\n" {
+			t.Errorf("incorrect output %q", out)
+		}
+	}
+}
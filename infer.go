@@ -0,0 +1,271 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// boolTokens are the pairs of values inferred as a bool column
+var boolTokens = [][2]string{
+	{"true", "false"}, {"True", "False"}, {"TRUE", "FALSE"},
+	{"yes", "no"}, {"Yes", "No"}, {"YES", "NO"},
+	{"y", "n"}, {"Y", "N"}, {"t", "f"}, {"T", "F"},
+}
+
+// timeLayouts are tried in order when inferring a time column
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"01/02/2006",
+	"02/01/2006",
+	"1/2/2006",
+	"02-Jan-2006",
+	"Jan 2, 2006",
+	time.RFC1123,
+}
+
+// column is what a sample shows of one column
+type column struct {
+	name   string
+	values []string // the non-empty values
+	empty  bool     // some values are empty
+}
+
+// InferStruct reads the header and up to n records from r, or every record
+// when n is 0, and returns the Go source of a struct type named name which
+// Unmarshal decodes the document into.
+//
+// Each column's type is the first of bool, int, float64, time.Time and string
+// which holds every sampled value. Bools get true and false tags with the
+// tokens found, times a layout tag, and a column with empty values is a
+// pointer unless it is a string. Field names are the headers made into Go
+// identifiers, with a csv tag when they differ. opts are the reading options,
+// such as Comma, WithDialect and AutoDetect, which are applied as Unmarshal
+// applies them. When the Dialect has no header the fields are named Column1,
+// Column2 and so on, the columns Unmarshal then expects.
+func InferStruct(r io.Reader, name string, n int, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	r = o.decodeCharset(r)
+
+	if o.detect || o.dialect != nil {
+		r, _, _ = o.readDialect(r)
+	}
+
+	cr := csv.NewReader(r)
+	o.applyReader(cr)
+
+	first, err := cr.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]column, len(first))
+	read := 0
+
+	add := func(rec []string) {
+		for i := range cols {
+			if o.protect() && i < len(rec) {
+				rec[i] = unprotect(rec[i])
+			}
+
+			if i >= len(rec) || rec[i] == "" {
+				cols[i].empty = true
+			} else {
+				cols[i].values = append(cols[i].values, rec[i])
+			}
+		}
+		read++
+	}
+
+	if o.header() {
+		for i, h := range first {
+			cols[i].name = h
+		}
+	} else {
+		for i := range cols {
+			cols[i].name = fmt.Sprintf("Column%d", i+1)
+		}
+		add(first)
+	}
+
+	for n <= 0 || read < n {
+		rec, err := cr.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		add(rec)
+	}
+
+	var b bytes.Buffer
+	used := map[string]bool{}
+
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+
+	for i, c := range cols {
+		field := uniqueIdent(goIdent(c.name, i), used)
+		typ, tags := c.infer()
+
+		if name, _ := splitTag(reflect.StructTag("csv:" + strconv.Quote(c.name))); c.name == "" || c.name == "-" || name != c.name {
+			fmt.Fprintf(&b, "// the column %s can not be named by a csv tag\n", strconv.Quote(c.name))
+			tags = append([]string{`csv:"-"`}, tags...)
+		} else if field != c.name {
+			tags = append([]string{"csv:" + strconv.Quote(c.name)}, tags...)
+		}
+
+		fmt.Fprintf(&b, "%s %s", field, typ)
+
+		if tag := strings.Join(tags, " "); tag != "" {
+			if strings.Contains(tag, "`") {
+				tag = strconv.Quote(tag)
+			} else {
+				tag = "`" + tag + "`"
+			}
+			fmt.Fprintf(&b, " %s", tag)
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString("}\n")
+
+	return format.Source(b.Bytes())
+}
+
+// infer returns the column's Go type and its tags.
+func (c column) infer() (string, []string) {
+	if len(c.values) == 0 {
+		return "string", nil
+	}
+
+	ptr := ""
+	if c.empty {
+		ptr = "*"
+	}
+
+	if tokens, ok := c.bools(); ok {
+		return ptr + "bool", []string{
+			"true:" + strconv.Quote(tokens[0]),
+			"false:" + strconv.Quote(tokens[1]),
+		}
+	}
+
+	if c.all(isInt) {
+		return ptr + "int", nil
+	}
+
+	if c.all(isFloat) {
+		return ptr + "float64", nil
+	}
+
+	for _, l := range timeLayouts {
+		if c.all(func(v string) bool { _, err := time.Parse(l, v); return err == nil }) {
+			return ptr + "time.Time", []string{"layout:" + strconv.Quote(l)}
+		}
+	}
+
+	return "string", nil
+}
+
+// bools returns the first pair of tokens holding every value.
+func (c column) bools() ([2]string, bool) {
+	for _, t := range boolTokens {
+		if c.all(func(v string) bool { return v == t[0] || v == t[1] }) {
+			return t, true
+		}
+	}
+
+	return [2]string{}, false
+}
+
+func (c column) all(fn func(string) bool) bool {
+	for _, v := range c.values {
+		if !fn(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// leadingZero reports if v has a leading zero which a number would lose, as
+// in a zip code.
+func leadingZero(v string) bool {
+	digits := strings.TrimLeft(v, "+-")
+	return len(digits) > 1 && digits[0] == '0' && digits[1] != '.'
+}
+
+func isInt(v string) bool {
+	if leadingZero(v) {
+		return false
+	}
+
+	_, err := strconv.Atoi(v)
+	return err == nil
+}
+
+// isFloat reports if v is a decimal float, leaving out the Inf, NaN and hex
+// forms strconv also accepts.
+func isFloat(v string) bool {
+	if leadingZero(v) || strings.Trim(v, "0123456789+-.eE") != "" {
+		return false
+	}
+
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+// goIdent makes an exported Go identifier of the column name, such as
+// "DeliveryDate" from "delivery date". i names a column without letters.
+func goIdent(name string, i int) string {
+	var b strings.Builder
+
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+
+	id := b.String()
+
+	switch {
+	case id == "":
+		return fmt.Sprintf("Column%d", i+1)
+	case !unicode.IsUpper([]rune(id)[0]):
+		return "X" + id
+	}
+
+	return id
+}
+
+// uniqueIdent numbers id when it is already used.
+func uniqueIdent(id string, used map[string]bool) string {
+	u := id
+
+	for n := 2; used[u]; n++ {
+		u = id + strconv.Itoa(n)
+	}
+
+	used[u] = true
+	return u
+}
@@ -0,0 +1,101 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInferStruct(t *testing.T) {
+	doc := `id,first name,Joined,score,Active,zip,Ratio,,id
+1,Ada,2024-01-02,9,Y,01234,0.5,x,3
+2,,2024-03-04,,N,99999,1e3,,4
+`
+
+	src, err := InferStruct(strings.NewReader(doc), "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "type Person struct {\n" +
+		"\tId        int       `csv:\"id\"`\n" +
+		"\tFirstName string    `csv:\"first name\"`\n" +
+		"\tJoined    time.Time `layout:\"2006-01-02\"`\n" +
+		"\tScore     *int      `csv:\"score\"`\n" +
+		"\tActive    bool      `true:\"Y\" false:\"N\"`\n" +
+		"\tZip       string    `csv:\"zip\"`\n" +
+		"\tRatio     float64\n" +
+		"\t// the column \"\" can not be named by a csv tag\n" +
+		"\tColumn8 string `csv:\"-\"`\n" +
+		"\tId2     int    `csv:\"id\"`\n" +
+		"}\n"
+
+	if string(src) != expected {
+		t.Errorf("incorrect struct\n%s", src)
+	}
+
+	// the sample is limited to n records
+	src, _ = InferStruct(strings.NewReader("A;B\n1;x\nx;2\n"), "T", 1, Comma(';'))
+
+	if !strings.Contains(string(src), "A int") {
+		t.Errorf("incorrect struct for a sample of 1\n%s", src)
+	}
+
+	// a comma in a column name is kept unless options follow it
+	src, _ = InferStruct(strings.NewReader("\"Last, First\",\"a,line\"\nx,z\n"), "T", 0)
+
+	if !strings.Contains(string(src), "LastFirst string `csv:\"Last, First\"`") || !strings.Contains(string(src), "ALine string `csv:\"-\"`") {
+		t.Errorf("incorrect struct for names with commas\n%s", src)
+	}
+	// an Excel export is read in its dialect
+	excel := "\xEF\xBB\xBFsep=;\r\nZip;Count\r\n\"=\"\"01234\"\"\";3\r\n"
+
+	for _, opt := range []Option{WithDialect(Excel), AutoDetect()} {
+		src, err = InferStruct(strings.NewReader(excel), "T", 0, opt)
+
+		if err != nil || string(src) != "type T struct {\n\tZip   string\n\tCount int\n}\n" {
+			t.Errorf("incorrect struct for an Excel export %v\n%s", err, src)
+		}
+	}
+
+	// without a header the fields are the columns Unmarshal expects
+	src, _ = InferStruct(strings.NewReader("1,x\n2,y\n"), "T", 0, WithDialect(Dialect{Comma: ','}))
+
+	if string(src) != "type T struct {\n\tColumn1 int\n\tColumn2 string\n}\n" {
+		t.Errorf("incorrect struct without a header\n%s", src)
+	}
+}
+
+// TestInferredDecode checks a document decodes into a type like the one
+// InferStruct writes for it.
+func TestInferredDecode(t *testing.T) {
+	type Person struct {
+		Id     int       `csv:"id"`
+		Score  *int      `csv:"score"`
+		Active *bool     `true:"Y" false:"N"`
+		Zip    string    `csv:"zip"`
+		Ratio  *float64  // a pointer to an unset float is nil
+		Joined time.Time `layout:"01/02/2006"`
+	}
+
+	doc := []byte("id,score,Active,zip,Ratio,Joined\n1,9,Y,01234,,12/31/2024\n2,,,99999,0.5,\n")
+
+	pp := []Person{}
+	if err := Unmarshal(doc, &pp); err != nil {
+		t.Fatal(err)
+	}
+
+	if *pp[0].Score != 9 || !*pp[0].Active || pp[0].Ratio != nil || pp[1].Score != nil || pp[1].Active != nil || *pp[1].Ratio != 0.5 ||
+		pp[0].Joined.Day() != 31 || !pp[1].Joined.IsZero() {
+		t.Errorf("incorrect decode %+v %+v", pp[0], pp[1])
+	}
+
+	out, err := Marshal(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != string(doc) {
+		t.Errorf("did not round trip %q", out)
+	}
+}
@@ -0,0 +1,216 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// numberFormat is how the numbers of a column are written. The zero value is
+// the format of strconv.
+type numberFormat struct {
+	decimal    string // the decimal separator, "." when empty
+	group      string // the thousands separator, none when empty
+	currency   string // a symbol before the number, or after it when it starts with a space
+	accounting bool   // negatives are written in parentheses
+	percent    bool   // the value is a fraction written as a percentage
+}
+
+// locales are the number formats named by the locale tag
+var locales = map[string]numberFormat{
+	"en": {decimal: ".", group: ","},
+	"de": {decimal: ",", group: "."},
+	"es": {decimal: ",", group: "."},
+	"it": {decimal: ",", group: "."},
+	"nl": {decimal: ",", group: "."},
+	"pt": {decimal: ",", group: "."},
+	"fr": {decimal: ",", group: " "},
+	"ch": {decimal: ".", group: "'"},
+}
+
+// numberFormatFor reads the locale tag, which the decimal and group tags
+// override, along with the currency tag and the accounting and percent
+// options. Only floats may be percentages.
+//
+//	Income int     `locale:"en"`                                           // 32,000
+//	Amount float64 `decimal:"," group:"."`                                 // 1.234,56
+//	Price  float64 `currency:"$" locale:"en" format:"%.2f" csv:",accounting"` // ($1,204.10)
+//	Rate   float64 `csv:",percent"`                                        // 12.5%
+func numberFormatFor(st reflect.StructTag, float bool) (numberFormat, error) {
+	var nf numberFormat
+
+	if l, ok := st.Lookup("locale"); ok {
+		if nf, ok = locales[l]; !ok {
+			return nf, fmt.Errorf("unknown locale %q", l)
+		}
+	}
+
+	if d, ok := st.Lookup("decimal"); ok {
+		nf.decimal = d
+	}
+
+	if g, ok := st.Lookup("group"); ok {
+		nf.group = g
+	}
+
+	if nf.decimal != "" && nf.decimal == nf.group {
+		return nf, fmt.Errorf("decimal and group separators are both %q", nf.decimal)
+	}
+
+	nf.currency = st.Get("currency")
+	nf.accounting = hasTagOption(st, "accounting")
+	nf.percent = hasTagOption(st, "percent")
+
+	if nf.percent && !float {
+		return nf, errors.New("the percent option requires a float field")
+	}
+
+	return nf, nil
+}
+
+func (nf numberFormat) isZero() bool {
+	return nf == numberFormat{} || nf == numberFormat{decimal: "."}
+}
+
+// parse converts s to the format strconv parses.
+func (nf numberFormat) parse(s string) string {
+	if nf.isZero() {
+		return s
+	}
+
+	s = strings.TrimSpace(s)
+	neg := false
+
+	if nf.accounting && strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	if c := strings.TrimSpace(nf.currency); c != "" {
+		s = strings.TrimSpace(strings.Replace(s, c, "", 1))
+	}
+
+	if nf.percent {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+	}
+
+	if nf.group != "" {
+		s = strings.ReplaceAll(s, nf.group, "")
+	}
+
+	if nf.decimal != "" && nf.decimal != "." {
+		s = strings.Replace(s, nf.decimal, ".", 1)
+	}
+
+	if nf.percent {
+		s = shiftPoint(s, -2)
+	}
+
+	if neg {
+		s = "-" + s
+	}
+
+	return s
+}
+
+// format converts s, a number written by strconv, to the format. A percent
+// must already be scaled.
+func (nf numberFormat) format(s string) string {
+	if nf.isZero() || s == "" {
+		return s
+	}
+
+	// the integer digits are those after any sign and before a decimal
+	// point or exponent
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < end; i++ {
+		if i > 0 && (end-i)%3 == 0 {
+			b.WriteString(nf.group)
+		}
+		b.WriteByte(s[i])
+	}
+
+	rest := s[end:]
+	if nf.decimal != "" && strings.HasPrefix(rest, ".") {
+		rest = nf.decimal + rest[1:]
+	}
+
+	b.WriteString(rest)
+
+	if nf.percent {
+		b.WriteString("%")
+	}
+
+	out := b.String()
+
+	switch {
+	case strings.HasPrefix(nf.currency, " "):
+		out += nf.currency
+	case nf.currency != "":
+		out = nf.currency + out
+	}
+
+	switch {
+	case neg && nf.accounting:
+		out = "(" + out + ")"
+	case neg:
+		out = "-" + out
+	}
+
+	return out
+}
+
+// shiftPoint moves the decimal point of the number s by n places, to the
+// right when n is positive. The digits are moved rather than multiplied so
+// no precision is lost.
+func shiftPoint(s string, n int) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return sign + s
+		}
+		return fmt.Sprintf("%s%se%+03d", sign, s[:i], e+n)
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	digits := whole + frac
+	point := len(whole) + n
+
+	if point < 1 {
+		digits = strings.Repeat("0", 1-point) + digits
+		point = 1
+	}
+
+	if point > len(digits) {
+		digits += strings.Repeat("0", point-len(digits))
+	}
+
+	whole = strings.TrimLeft(digits[:point], "0")
+	if whole == "" {
+		whole = "0"
+	}
+
+	if frac = digits[point:]; frac != "" {
+		return sign + whole + "." + frac
+	}
+
+	return sign + whole
+}
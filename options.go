@@ -0,0 +1,118 @@
+package csv
+
+import (
+	"encoding/csv"
+)
+
+// Option configures how a document is read or written.
+type Option func(*options)
+
+type options struct {
+	comma   rune // field delimiter, defaults to ','
+	comment rune // comment character for input, disabled when 0
+	reuse   bool // reuse records, rows and the output slice when reading
+	workers int  // goroutines used to convert rows
+	float   floatFormat
+	collect bool     // row errors are collected rather than returned
+	detect  bool     // the Dialect is detected before reading
+	dialect *Dialect // set by WithDialect or AutoDetect
+	charset Charset  // the character encoding of documents
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{comma: ','}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Comma sets the field delimiter. It defaults to ','.
+func Comma(r rune) Option {
+	return func(o *options) {
+		o.comma = r
+	}
+}
+
+// Comment sets the character which starts a comment line when reading.
+// Comments are disabled by default.
+func Comment(r rune) Option {
+	return func(o *options) {
+		o.comment = r
+	}
+}
+
+// ReuseRecord decodes with as few allocations as possible. The csv.Reader's
+// ReuseRecord is enabled, one Row is shared by every record and Unmarshal
+// grows the output slice once and decodes straight into its elements.
+//
+// encoding/csv allocates a single string for each record, so string fields
+// keep the values read without copying. Only [][]string and []Row outputs
+// copy the reused record. An Unmarshaler must not keep the *Row it is given.
+func ReuseRecord() Option {
+	return func(o *options) {
+		o.reuse = true
+	}
+}
+
+// Concurrency converts rows with n goroutines.
+//
+// When reading, records are still read in order by a single goroutine, and the
+// results and errors are returned in the same order as the serial path.
+// Unmarshalers must be safe to call concurrently. ReuseRecord has no effect
+// when n is greater than 1.
+//
+// When writing, the slice is encoded in chunks whose output is joined in
+// order, which is byte for byte the same as the serial output. Marshalers must
+// be safe to call concurrently.
+func Concurrency(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// FloatFormat sets the strconv.FormatFloat format and precision used for float
+// and complex fields without their own fmt or precision tags. The default for
+// floats is 'g' with the smallest precision which represents the value
+// exactly, and FloatFormat('g', -1) makes complex fields lossless too. Types
+// implementing RowMarshaler format their own values.
+func FloatFormat(fmt byte, prec int) Option {
+	return func(o *options) {
+		o.float = floatFormat{fmt: fmt, prec: prec, hasPrec: true}
+	}
+}
+
+// CollectErrors continues past rows which fail to convert. The rows are left
+// out and their errors are returned together, joined with errors.Join, once
+// the whole document is read or written, and Marshal returns the rows it
+// wrote along with them. Errors from the csv.Reader or the output still stop
+// at once.
+func CollectErrors() Option {
+	return func(o *options) {
+		o.collect = true
+	}
+}
+
+func (o *options) applyReader(r *csv.Reader) {
+	r.Comma = o.comma
+	r.Comment = o.comment
+	r.ReuseRecord = o.reuse
+	r.LazyQuotes = o.protect()
+}
+
+func (o *options) applyWriter(w *csv.Writer) {
+	w.Comma = o.comma
+	w.UseCRLF = o.dialect != nil && o.dialect.CRLF
+}
+
+// header reports if documents have a header line
+func (o *options) header() bool {
+	return o.dialect == nil || o.dialect.Header
+}
+
+// protect reports if cells are protected from conversion by Excel
+func (o *options) protect() bool {
+	return o.dialect != nil && o.dialect.Protect
+}
@@ -0,0 +1,136 @@
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Plans are computed once for each struct type (and header line when
+// decoding) and shared by every Marshal, Unmarshal and streaming call.
+// Everything in a plan is read only once it is stored.
+
+// planLimit caps the number of decode plans so documents with arbitrary
+// headers can not grow the cache without bound. The cache is emptied when it
+// is full, so the headers in use are cached again.
+const planLimit = 1024
+
+type planKey struct {
+	reflect.Type
+	header string // the header line as headerKey encodes it
+}
+
+var (
+	rowMarshalerType   = reflect.TypeOf(new(RowMarshaler)).Elem()
+	rowUnmarshalerType = reflect.TypeOf(new(RowUnmarshaler)).Elem()
+)
+
+var (
+	decodePlans sync.Map // planKey -> []cfield
+	encodePlans sync.Map // reflect.Type -> *encodePlan
+	decodeCount int64
+)
+
+// cfieldsFor returns the cfields mapping the columns to the decoder's Type.
+func (dec *decoder) cfieldsFor(cols []string) []cfield {
+	key := planKey{dec.Type, headerKey(cols)}
+
+	if p, ok := decodePlans.Load(key); ok {
+		return p.([]cfield)
+	}
+
+	dec.mapFieldsToCols(cols)
+
+	if atomic.AddInt64(&decodeCount, 1) > planLimit {
+		decodePlans.Clear()
+		atomic.StoreInt64(&decodeCount, 1)
+	}
+
+	decodePlans.Store(key, dec.cfields)
+
+	return dec.cfields
+}
+
+// headerKey encodes cols with the length of each column first, so no two
+// headers have the same key.
+func headerKey(cols []string) string {
+	var b strings.Builder
+
+	for _, c := range cols {
+		b.WriteString(strconv.Itoa(len(c)))
+		b.WriteByte(':')
+		b.WriteString(c)
+	}
+
+	return b.String()
+}
+
+// encodePlan holds the header and encoded fields of a struct type.
+type encodePlan struct {
+	reflect.Type
+	cols   []string
+	fields []efield
+	rows   bool  // the type is a RowMarshaler
+	before bool  // the type is a BeforeMarshaler
+	err    error // the first invalid tag, returned before encoding
+}
+
+// efield is a struct field written as a column
+type efield struct {
+	index   []int // the field's index path in the struct
+	encode  encoderFn
+	columns []string // the columns of a ColumnsMarshaler
+	multi   bool     // the field is a ColumnsMarshaler
+	field   *Field   // the descriptor given to a FieldMarshaler
+}
+
+// encodePlanFor returns the cached encodePlan for t.
+func encodePlanFor(t reflect.Type) *encodePlan {
+	if p, ok := encodePlans.Load(t); ok {
+		return p.(*encodePlan)
+	}
+
+	p := &encodePlan{Type: t, before: implementsEither(t, beforeMarshalerType)}
+
+	if t.Implements(rowMarshalerType) {
+		p.rows = true
+		p.cols = reflect.Zero(t).Interface().(RowMarshaler).CSVHeader()
+	} else {
+		p.cols = colNames(t)
+	}
+
+	col := 0
+
+	for x := 0; x < t.NumField(); x++ {
+		f := t.Field(x)
+
+		if name, ok := fieldHeaderName(f); ok && !p.rows {
+			e, err := encoderFor(f.Type, f.Tag)
+
+			if err != nil && p.err == nil {
+				p.err = fmt.Errorf("field %s: %w", f.Name, err)
+			}
+
+			ef := efield{index: f.Index, encode: e}
+			ef.columns, ef.multi = fieldColumns(f.Type)
+
+			if ef.multi {
+				col += len(ef.columns)
+			} else {
+				if implementsEither(f.Type, fieldMarshalerType) {
+					d := newField(&f, name, col)
+					ef.field = &d
+				}
+				col++
+			}
+
+			p.fields = append(p.fields, ef)
+		}
+	}
+
+	actual, _ := encodePlans.LoadOrStore(t, p)
+	return actual.(*encodePlan)
+}
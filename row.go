@@ -0,0 +1,87 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// columnIndex maps each column name to its first position, as Named finds it.
+func columnIndex(cols []string) map[string]int {
+	index := make(map[string]int, len(cols))
+
+	for i := len(cols) - 1; i >= 0; i-- {
+		index[cols[i]] = i
+	}
+
+	return index
+}
+
+// Len returns the number of values in the row.
+func (r *Row) Len() int {
+	return len(r.Data)
+}
+
+// Get returns the value of the first column named n, reporting if there is
+// one.
+func (r *Row) Get(n string) (string, bool) {
+	v, err := r.Named(n)
+	return v, err == nil
+}
+
+// Has reports if the row has a column named n.
+func (r *Row) Has(n string) bool {
+	_, ok := r.Get(n)
+	return ok
+}
+
+// Int returns the value of the column named n as an int, converted as an int
+// field is.
+func (r *Row) Int(n string) (int, error) {
+	v, err := r.Named(n)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(v)
+}
+
+// Float returns the value of the column named n as a float64, converted as a
+// float64 field is.
+func (r *Row) Float(n string) (float64, error) {
+	v, err := r.Named(n)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(v, 64)
+}
+
+// Bool returns the value of the column named n as a bool. Without tokens any
+// value strconv.ParseBool accepts is read. Given true and false tokens, such as
+// "Y" and "N", it is converted as a bool field with those tags is.
+func (r *Row) Bool(n string, tokens ...string) (bool, error) {
+	v, err := r.Named(n)
+	if err != nil {
+		return false, err
+	}
+
+	switch len(tokens) {
+	case 0:
+		return strconv.ParseBool(v)
+	case 2:
+		return boolValue(v, [2]string{tokens[0], tokens[1]}), nil
+	}
+
+	return false, fmt.Errorf("Bool takes a true and a false token, not %d tokens", len(tokens))
+}
+
+// Time returns the value of the column named n parsed with layout.
+func (r *Row) Time(n, layout string) (time.Time, error) {
+	v, err := r.Named(n)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(layout, v)
+}
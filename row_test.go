@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRowAccessors(t *testing.T) {
+	rows := []Row{}
+	doc := []byte(`Name,Age,Score,Member,Joined,Name
+Ada,36,9.5,Y,1843-10-01,Byron
+`)
+
+	if err := Unmarshal(doc, &rows); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &rows[0]
+
+	if r.Line != 2 || r.Len() != 6 {
+		t.Errorf("incorrect Line %d or Len %d", r.Line, r.Len())
+	}
+
+	if v, ok := r.Get("Name"); !ok || v != "Ada" {
+		t.Errorf("Get returned the wrong column %q", v)
+	}
+
+	if r.Has("Missing") || !r.Has("Score") {
+		t.Error("Has is incorrect")
+	}
+
+	if n, err := r.Int("Age"); err != nil || n != 36 {
+		t.Errorf("Int returned %d %v", n, err)
+	}
+
+	if f, err := r.Float("Score"); err != nil || f != 9.5 {
+		t.Errorf("Float returned %v %v", f, err)
+	}
+
+	if b, err := r.Bool("Member", "Y", "N"); err != nil || !b {
+		t.Errorf("Bool returned %v %v", b, err)
+	}
+
+	if _, err := r.Bool("Member"); err == nil {
+		t.Error("no error for a bool without tokens")
+	}
+
+	if d, err := r.Time("Joined", time.DateOnly); err != nil || d.Year() != 1843 {
+		t.Errorf("Time returned %v %v", d, err)
+	}
+
+	if _, err := r.Int("Missing"); err == nil {
+		t.Error("no error for a missing column")
+	}
+
+	// a Row built by hand has no index
+	cols := []string{"A", "B", "A"}
+	hand := Row{Columns: &cols, Data: []string{"1", "2", "3"}}
+
+	if v, _ := hand.Named("A"); v != "1" {
+		t.Errorf("Named returned %q", v)
+	}
+}
@@ -0,0 +1,55 @@
+package csv
+
+import (
+	"reflect"
+	"time"
+)
+
+// time.Time fields are read and written with the layout tag, which defaults
+// to time.RFC3339. An empty cell is the zero time.
+//
+//	Joined time.Time `layout:"01/02/2006"`
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func timeLayout(st reflect.StructTag) string {
+	if l := st.Get("layout"); l != "" {
+		return l
+	}
+
+	return time.RFC3339
+}
+
+func (cf *cfield) decodeTime(layout string) decoderFn {
+	return func(cell reflect.Value, row *Row) error {
+		val := row.At(cf.colIndex)
+
+		if val == "" {
+			cell.SetZero()
+			return nil
+		}
+
+		t, err := time.Parse(layout, val)
+
+		if err != nil {
+			return err
+		}
+
+		cell.Set(reflect.ValueOf(t))
+		return nil
+	}
+}
+
+func encodeTime(st reflect.StructTag) encoderFn {
+	layout := timeLayout(st)
+
+	return func(enc *encoder, fv reflect.Value) string {
+		t := fv.Interface().(time.Time)
+
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format(layout)
+	}
+}
@@ -0,0 +1,60 @@
+package csv
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTimeFields checks time.Time fields, which were once written as empty
+// cells and ignored when decoding, are written and parsed as RFC3339 unless
+// a layout tag is set.
+func TestTimeFields(t *testing.T) {
+	type T struct {
+		At  time.Time
+		Day time.Time `layout:"02/01/2006"`
+	}
+
+	at := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	out, err := Marshal([]T{{at, at}, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "At,Day\n2024-01-02T15:04:05Z,02/01/2024\n,\n" {
+		t.Errorf("incorrect output %q", out)
+	}
+
+	tt := []T{}
+	if err := Unmarshal(out, &tt); err != nil || !tt[0].At.Equal(at) || !tt[1].At.IsZero() {
+		t.Errorf("incorrect rows %+v %v", tt, err)
+	}
+
+	// other formats need a layout tag
+	var de *DecodeError
+	if err := Unmarshal([]byte("At,Day\n01/02/2024,\n"), &tt); !errors.As(err, &de) || de.Column != "At" {
+		t.Errorf("expected a DecodeError for At got %v", err)
+	}
+}
+
+// TestPointerFields checks pointer fields, which once panicked, are written
+// as their value and nil is an empty cell both ways.
+func TestPointerFields(t *testing.T) {
+	type P struct {
+		N *int
+		S *string
+	}
+
+	n, s := 3, "x"
+
+	out, err := Marshal([]P{{&n, &s}, {}})
+	if err != nil || string(out) != "N,S\n3,x\n,\n" {
+		t.Errorf("incorrect output %q %v", out, err)
+	}
+
+	pp := []P{}
+	if err := Unmarshal(out, &pp); err != nil || *pp[0].N != 3 || *pp[0].S != "x" || pp[1].N != nil || pp[1].S != nil {
+		t.Errorf("incorrect rows %+v %v", pp, err)
+	}
+}
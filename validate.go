@@ -0,0 +1,176 @@
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validation rules are compiled with the decode plan. min and max compare the
+// decoded number of an int, uint or float field, the other rules test the
+// text of the cell.
+
+// ValidationError reports a value which failed a validation rule.
+type ValidationError struct {
+	Rule  string // the rule as written in the tag, such as "max=9999"
+	Value string // the text of the cell
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%q fails %s", e.Value, e.Rule)
+}
+
+// rule is a compiled validation rule
+type rule struct {
+	name  string
+	check func(val string, cell reflect.Value) bool
+}
+
+// rulesFor compiles the validation tags of sf.
+func rulesFor(sf *reflect.StructField) ([]rule, error) {
+	var rules []rule
+	st := sf.Tag
+
+	if v := st.Get("validate"); v != "" {
+		for _, r := range strings.Split(v, ",") {
+			name, arg, _ := strings.Cut(r, "=")
+
+			switch name {
+			case "notempty":
+				rules = append(rules, rule{r, func(val string, _ reflect.Value) bool {
+					return val != ""
+				}})
+			case "min", "max":
+				c, err := compareRule(sf.Type, name == "min", arg)
+				if err != nil {
+					return nil, err
+				}
+				rules = append(rules, rule{r, c})
+			default:
+				return nil, fmt.Errorf("unknown validation rule %q", r)
+			}
+		}
+	}
+
+	if v, ok := st.Lookup("oneof"); ok {
+		set := strings.Split(v, "|")
+		rules = append(rules, rule{"oneof=" + v, func(val string, _ reflect.Value) bool {
+			for _, s := range set {
+				if val == s {
+					return true
+				}
+			}
+			return false
+		}})
+	}
+
+	if v, ok := st.Lookup("regex"); ok {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex tag: %v", err)
+		}
+		rules = append(rules, rule{"regex=" + v, func(val string, _ reflect.Value) bool {
+			return re.MatchString(val)
+		}})
+	}
+
+	if v, ok := st.Lookup("len"); ok {
+		lo, hi, err := lenRange(v)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule{"len=" + v, func(val string, _ reflect.Value) bool {
+			n := utf8.RuneCountInString(val)
+			return n >= lo && n <= hi
+		}})
+	}
+
+	return rules, nil
+}
+
+// compareRule returns the check for a min or max bound of a numeric field. A
+// nil pointer field passes.
+func compareRule(t reflect.Type, min bool, arg string) (func(string, reflect.Value) bool, error) {
+	if t.Kind() == reflect.Ptr {
+		check, err := compareRule(t.Elem(), min, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(val string, v reflect.Value) bool {
+			return v.IsNil() || check(val, v.Elem())
+		}, nil
+	}
+
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bound %q", arg)
+	}
+
+	var value func(reflect.Value) float64
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = func(v reflect.Value) float64 { return float64(v.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = func(v reflect.Value) float64 { return float64(v.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		value = func(v reflect.Value) float64 { return v.Float() }
+	default:
+		return nil, fmt.Errorf("min and max require a numeric field, not %s", t)
+	}
+
+	if min {
+		return func(_ string, v reflect.Value) bool { return value(v) >= bound }, nil
+	}
+
+	return func(_ string, v reflect.Value) bool { return value(v) <= bound }, nil
+}
+
+// lenRange parses a len tag, either "n" or "lo..hi"
+func lenRange(s string) (int, int, error) {
+	a, b, ok := strings.Cut(s, "..")
+	if !ok {
+		b = a
+	}
+
+	lo, err1 := strconv.Atoi(a)
+	hi, err2 := strconv.Atoi(b)
+
+	if err1 != nil || err2 != nil || lo < 0 || hi < lo {
+		return 0, 0, fmt.Errorf("invalid len tag %q", s)
+	}
+
+	return lo, hi, nil
+}
+
+// validate returns a *ValidationError for the first rule the cell fails.
+func (cf *cfield) validate(cell reflect.Value, row *Row) error {
+	val := row.At(cf.colIndex)
+
+	for _, r := range cf.rules {
+		if !r.check(val, cell) {
+			return &ValidationError{Rule: r.name, Value: val}
+		}
+	}
+
+	return nil
+}
+
+// assignRules compiles the field's validation tags. An invalid tag is
+// reported by every call of the decoder.
+func (cf *cfield) assignRules() {
+	rules, err := rulesFor(cf.structField)
+
+	if err != nil {
+		cf.decoder = func(reflect.Value, *Row) error {
+			return err
+		}
+		return
+	}
+
+	cf.rules = rules
+}